@@ -0,0 +1,631 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/binkynet/BinkyNet/discovery"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	terminate "github.com/pulcy/go-terminate"
+	"github.com/rs/zerolog"
+	"github.com/spf13/pflag"
+
+	"github.com/binkynet/NetManager/service/control"
+	"github.com/binkynet/NetManager/service/util"
+)
+
+const (
+	// Time to wait for the result of a discovery
+	clientDiscoverTimeout = time.Second * 30
+)
+
+// clientCommand is a subcommand of the client that talks to a running manager.
+type clientCommand struct {
+	// Name of the command (one or more words)
+	Name string
+	// Usage of the arguments following the name
+	Args string
+	// Number of arguments following the name
+	NumArgs int
+	// Short description
+	Description string
+	// Run the command
+	Run func(ctx context.Context, c *client, args []string) error
+}
+
+var clientCommands = []clientCommand{
+	{"workers list", "", 0, "List all local workers", runWorkersList},
+	{"workers show", "<id>", 1, "Show a local worker", runWorkersShow},
+	{"workers reset", "<id|all>", 1, "Reset a local worker (or all local workers)", runWorkersReset},
+	{"workers reconfigure", "all", 1, "Force all local workers to reconfigure (requires a state store)", runWorkersReconfigure},
+	{"workers history", "<id>", 1, "List the revisions of the configuration of a local worker", runWorkersHistory},
+	{"workers rollback", "<id> <revision|latest>", 2, "Configure a local worker with its configuration at a revision", runWorkersRollback},
+	{"discover", "<id>", 1, "Discover the devices of a local worker", runDiscover},
+	{"power", "on|off", 1, "Turn the power on or off", runPower},
+	{"switch set", "<address> <straight|off>", 2, "Set the requested direction of a switch", runSwitchSet},
+	{"output set", "<address> <value>", 2, "Set the requested value of an output", runOutputSet},
+	{"divergent", "", 0, "List all objects of which the actual state differs from the requested state", runDivergent},
+	{"watch", "<workers|liveness|power|locs|switches|outputs|sensors|clock>", 1, "Watch changes in a domain", runWatch},
+}
+
+// isClientCommand returns true if the given (first) argument
+// selects a client command.
+func isClientCommand(arg string) bool {
+	for _, cmd := range clientCommands {
+		if strings.Fields(cmd.Name)[0] == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// client holds the connection to a running manager & the output options.
+type client struct {
+	control.LayoutControlServiceClient
+	nwControl api.NetworkControlServiceClient
+	out       io.Writer
+	json      bool
+	moduleID  string
+	marshaler jsonpb.Marshaler
+}
+
+// runClient parses the given arguments, connects to the manager & runs
+// the selected client command.
+func runClient(args []string) error {
+	var managerAddress string
+	var secure bool
+	var tlsConf util.ClientTLSConfig
+	var output string
+	var discoveryTimeout time.Duration
+	var moduleID string
+
+	fs := pflag.NewFlagSet("client", pflag.ContinueOnError)
+	fs.StringVar(&managerAddress, "manager", "", "Address (host[:port]) of the manager (empty to find it using zeroconf)")
+	fs.BoolVar(&secure, "secure", false, "Use TLS to connect to the manager given in --manager")
+	fs.StringVar(&tlsConf.CAFile, "tls-ca", "", "CA bundle used to verify a secure manager (defaults to system roots)")
+	fs.StringVarP(&output, "output", "o", "table", "Output format (table|json)")
+	fs.DurationVar(&discoveryTimeout, "discovery-timeout", time.Second*5, "Time to wait for the manager to be found using zeroconf")
+	fs.StringVar(&moduleID, "module", "", "Only watch objects of this module")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+		w := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+		for _, cmd := range clientCommands {
+			fmt.Fprintf(w, "  %s %s\t%s\n", cmd.Name, cmd.Args, cmd.Description)
+		}
+		w.Flush()
+		fmt.Fprintf(os.Stderr, "\nFlags:\n%s", fs.FlagUsages())
+	}
+	if err := fs.Parse(args); err == pflag.ErrHelp {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if output != "table" && output != "json" {
+		return fmt.Errorf("unknown output format '%s'", output)
+	}
+
+	// Find command
+	cmd, cmdArgs, found := findClientCommand(fs.Args())
+	if !found {
+		fs.Usage()
+		return fmt.Errorf("unknown command '%s'", strings.Join(fs.Args(), " "))
+	}
+	if len(cmdArgs) != cmd.NumArgs {
+		return fmt.Errorf("usage: %s %s %s", os.Args[0], cmd.Name, cmd.Args)
+	}
+
+	// Prepare to stop in a controlled manor
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	t := terminate.NewTerminator(func(template string, args ...interface{}) {}, cancel)
+	go t.ListenSignals()
+
+	// Find & connect to manager
+	log := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).Level(zerolog.WarnLevel)
+	host, port := managerAddress, defaultGrpcPort
+	if managerAddress == "" {
+		info, err := findManager(ctx, log, discoveryTimeout)
+		if err != nil {
+			return err
+		}
+		host, port, secure = info.GetApiAddress(), int(info.GetApiPort()), info.GetSecure()
+	} else if h, p, err := net.SplitHostPort(managerAddress); err == nil {
+		host = h
+		if port, err = strconv.Atoi(p); err != nil {
+			return fmt.Errorf("invalid port in '%s': %w", managerAddress, err)
+		}
+	}
+	tlsConfig, err := util.NewClientTLS(tlsConf)
+	if err != nil {
+		return err
+	}
+	conn, err := util.DialConn(host, port, secure, tlsConfig, host)
+	if err != nil {
+		return fmt.Errorf("failed to connect to manager at %s: %w", net.JoinHostPort(host, strconv.Itoa(port)), err)
+	}
+	defer conn.Close()
+
+	c := &client{
+		LayoutControlServiceClient: control.NewLayoutControlServiceClient(conn),
+		nwControl:                  api.NewNetworkControlServiceClient(conn),
+		out:                        os.Stdout,
+		json:                       output == "json",
+		moduleID:                   moduleID,
+		marshaler:                  jsonpb.Marshaler{OrigName: true, EmitDefaults: true},
+	}
+	return cmd.Run(ctx, c, cmdArgs)
+}
+
+// findClientCommand returns the command selected by the given arguments,
+// followed by the remaining arguments.
+func findClientCommand(args []string) (clientCommand, []string, bool) {
+	for _, cmd := range clientCommands {
+		words := strings.Fields(cmd.Name)
+		if len(args) < len(words) || strings.Join(args[:len(words)], " ") != cmd.Name {
+			continue
+		}
+		return cmd, args[len(words):], true
+	}
+	return clientCommand{}, nil, false
+}
+
+// findManager waits for the zeroconf service entry of a manager.
+func findManager(ctx context.Context, log zerolog.Logger, timeout time.Duration) (api.ServiceInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	found := make(chan api.ServiceInfo, 1)
+	failed := make(chan error, 1)
+	listener := discovery.NewServiceListener(log, api.ServiceTypeNetworkControl, false, func(info api.ServiceInfo) {
+		select {
+		case found <- info:
+		default:
+		}
+	})
+	go func() {
+		if err := listener.Run(ctx); err != nil {
+			failed <- err
+		}
+	}()
+	select {
+	case info := <-found:
+		return info, nil
+	case err := <-failed:
+		return api.ServiceInfo{}, fmt.Errorf("failed to find manager: %w", err)
+	case <-ctx.Done():
+		return api.ServiceInfo{}, fmt.Errorf("no manager found within %s (use --manager to specify its address)", timeout)
+	}
+}
+
+// workers list
+func runWorkersList(ctx context.Context, c *client, args []string) error {
+	workers, err := c.listWorkers(ctx)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printMessages(c, workers)
+	}
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tALIAS\tVERSION\tUPTIME\tCONFIG")
+	for _, lw := range workers {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", lw.GetId(), lw.GetRequest().GetAlias(),
+			lw.GetActual().GetVersion(), formatUptime(lw.GetActual()), configStatus(lw))
+	}
+	return w.Flush()
+}
+
+// workers show <id>
+func runWorkersShow(ctx context.Context, c *client, args []string) error {
+	lw, err := c.GetWorker(ctx, &api.LocalWorker{Id: args[0]})
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printMessage(lw)
+	}
+	info, conf := lw.GetActual(), lw.GetRequest()
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", lw.GetId())
+	fmt.Fprintf(w, "Alias:\t%s\n", conf.GetAlias())
+	fmt.Fprintf(w, "Description:\t%s\n", info.GetDescription())
+	fmt.Fprintf(w, "Version:\t%s\n", info.GetVersion())
+	fmt.Fprintf(w, "Uptime:\t%s\n", formatUptime(info))
+	fmt.Fprintf(w, "Config:\t%s\n", configStatus(*lw))
+	fmt.Fprintf(w, "Requested config hash:\t%s\n", conf.GetHash())
+	fmt.Fprintf(w, "Actual config hash:\t%s\n", info.GetConfigHash())
+	fmt.Fprintf(w, "Requested devices:\t%d\n", len(conf.GetDevices()))
+	fmt.Fprintf(w, "Requested objects:\t%d\n", len(conf.GetObjects()))
+	fmt.Fprintf(w, "Configured devices:\t%s\n", strings.Join(info.GetConfiguredDeviceIds(), ", "))
+	fmt.Fprintf(w, "Configured objects:\t%s\n", strings.Join(info.GetConfiguredObjectIds(), ", "))
+	fmt.Fprintf(w, "Unconfigured devices:\t%s\n", strings.Join(info.GetUnconfiguredDeviceIds(), ", "))
+	fmt.Fprintf(w, "Unconfigured objects:\t%s\n", strings.Join(info.GetUnconfiguredObjectIds(), ", "))
+	return w.Flush()
+}
+
+// workers reset <id|all>
+func runWorkersReset(ctx context.Context, c *client, args []string) error {
+	ids := []string{args[0]}
+	if args[0] == "all" {
+		workers, err := c.listWorkers(ctx)
+		if err != nil {
+			return err
+		}
+		ids = ids[:0]
+		for _, lw := range workers {
+			ids = append(ids, lw.GetId())
+		}
+	}
+	// Continue with the other local workers when one fails
+	var failed []string
+	var lastErr error
+	for _, id := range ids {
+		if _, err := c.ResetWorker(ctx, &api.LocalWorker{Id: id}); err != nil {
+			failed = append(failed, id)
+			lastErr = fmt.Errorf("failed to reset '%s': %w", id, err)
+			if len(ids) > 1 {
+				fmt.Fprintln(os.Stderr, lastErr)
+			}
+			continue
+		}
+		if !c.json {
+			fmt.Fprintf(c.out, "Requested reset of %s\n", id)
+		}
+	}
+	if len(failed) > 1 {
+		return fmt.Errorf("failed to reset %d of %d local workers: %s", len(failed), len(ids), strings.Join(failed, ", "))
+	}
+	return lastErr
+}
+
+// workers reconfigure all
+func runWorkersReconfigure(ctx context.Context, c *client, args []string) error {
+	if args[0] != "all" {
+		return fmt.Errorf("expected 'all', got '%s'", args[0])
+	}
+	if _, err := c.ReconfigureAllWorkers(ctx, &api.Empty{}); err != nil {
+		return err
+	}
+	if !c.json {
+		fmt.Fprintln(c.out, "Requested reconfiguration of all local workers")
+	}
+	return nil
+}
+
+// workers history <id>
+func runWorkersHistory(ctx context.Context, c *client, args []string) error {
+	history, err := c.GetWorkerConfigHistory(ctx, &api.LocalWorker{Id: args[0]})
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printMessage(history)
+	}
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTIVE\tREVISION\tTIME\tAUTHOR\tSUBJECT")
+	for _, rev := range history.GetRevisions() {
+		active := ""
+		if rev.GetActive() {
+			active = "*"
+		}
+		hash := rev.GetHash()
+		if len(hash) > 7 {
+			hash = hash[:7]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", active, hash,
+			time.Unix(rev.GetUnixtime(), 0).Format(time.DateTime), rev.GetAuthor(), rev.GetSubject())
+	}
+	return w.Flush()
+}
+
+// workers rollback <id> <revision|latest>
+func runWorkersRollback(ctx context.Context, c *client, args []string) error {
+	revision := args[1]
+	if revision == "latest" {
+		// Follow the latest configuration again
+		revision = ""
+	}
+	if _, err := c.RollbackWorkerConfig(ctx, &control.RollbackWorkerConfigRequest{
+		Id:       args[0],
+		Revision: revision,
+	}); err != nil {
+		return err
+	}
+	if !c.json {
+		fmt.Fprintf(c.out, "Rolled back %s to %s\n", args[0], args[1])
+	}
+	return nil
+}
+
+// discover <id>
+func runDiscover(ctx context.Context, c *client, args []string) error {
+	ctx, cancel := context.WithTimeout(ctx, clientDiscoverTimeout)
+	defer cancel()
+	result, err := c.DiscoverWorker(ctx, &api.LocalWorker{Id: args[0]})
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return c.printMessage(result)
+	}
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS")
+	for _, addr := range result.GetAddresses() {
+		fmt.Fprintln(w, addr)
+	}
+	return w.Flush()
+}
+
+// power on|off
+func runPower(ctx context.Context, c *client, args []string) error {
+	var enabled bool
+	switch args[0] {
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		return fmt.Errorf("invalid power state '%s', expected on or off", args[0])
+	}
+	_, err := c.SetPowerRequest(ctx, &api.PowerState{Enabled: enabled})
+	return err
+}
+
+// switch set <address> <straight|off>
+func runSwitchSet(ctx context.Context, c *client, args []string) error {
+	direction, found := api.SwitchDirection_value[strings.ToUpper(args[1])]
+	if !found {
+		return fmt.Errorf("invalid switch direction '%s', expected straight or off", args[1])
+	}
+	_, err := c.SetSwitchRequest(ctx, &api.Switch{
+		Address: api.ObjectAddress(args[0]),
+		Request: &api.SwitchState{Direction: api.SwitchDirection(direction)},
+	})
+	return err
+}
+
+// output set <address> <value>
+func runOutputSet(ctx context.Context, c *client, args []string) error {
+	value, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid output value '%s': %w", args[1], err)
+	}
+	_, err = c.SetOutputRequest(ctx, &api.Output{
+		Address: api.ObjectAddress(args[0]),
+		Request: &api.OutputState{Value: int32(value)},
+	})
+	return err
+}
+
+// divergent
+func runDivergent(ctx context.Context, c *client, args []string) error {
+	list, err := c.listDivergentObjects(ctx)
+	if err != nil {
+		return err
+	}
+	if c.json {
+		return printMessages(c, list)
+	}
+	w := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DOMAIN\tADDRESS\tSINCE\tATTEMPTS\tNEXT ATTEMPT")
+	for _, x := range list {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", x.GetDomain(), x.GetAddress(),
+			time.Unix(x.GetSinceUnixtime(), 0).Format(time.DateTime), x.GetAttempts(),
+			time.Unix(x.GetNextAttemptUnixtime(), 0).Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+// watch <domain>
+func runWatch(ctx context.Context, c *client, args []string) error {
+	opts := &api.WatchOptions{
+		WatchRequestChanges: true,
+		WatchActualChanges:  true,
+		ModuleId:            c.moduleID,
+	}
+	switch args[0] {
+	case "workers":
+		stream, err := c.nwControl.WatchLocalWorkers(ctx, opts)
+		return watchStream(ctx, c, stream, err, func(x *api.LocalWorker) []string {
+			return []string{x.GetId(), formatState(x.GetRequest()), formatState(x.GetActual())}
+		})
+	case "liveness":
+		stream, err := c.WatchWorkerLiveness(ctx, opts)
+		return watchStream(ctx, c, stream, err, func(x *control.WorkerLiveness) []string {
+			return []string{x.GetId(), x.GetLiveness(), time.Unix(x.GetLastUpdatedUnixtime(), 0).Format(time.DateTime)}
+		})
+	case "power":
+		stream, err := c.WatchPower(ctx, opts)
+		return watchStream(ctx, c, stream, err, func(x *api.Power) []string {
+			return []string{formatState(x.GetRequest()), formatState(x.GetActual())}
+		})
+	case "locs":
+		stream, err := c.WatchLocs(ctx, opts)
+		return watchStream(ctx, c, stream, err, func(x *api.Loc) []string {
+			return []string{string(x.GetAddress()), formatState(x.GetRequest()), formatState(x.GetActual())}
+		})
+	case "switches":
+		stream, err := c.WatchSwitches(ctx, opts)
+		return watchStream(ctx, c, stream, err, func(x *api.Switch) []string {
+			return []string{string(x.GetAddress()), formatState(x.GetRequest()), formatState(x.GetActual())}
+		})
+	case "outputs":
+		stream, err := c.WatchOutputs(ctx, opts)
+		return watchStream(ctx, c, stream, err, func(x *api.Output) []string {
+			return []string{string(x.GetAddress()), formatState(x.GetRequest()), formatState(x.GetActual())}
+		})
+	case "sensors":
+		stream, err := c.WatchSensors(ctx, opts)
+		return watchStream(ctx, c, stream, err, func(x *api.Sensor) []string {
+			return []string{string(x.GetAddress()), formatState(x.GetActual())}
+		})
+	case "clock":
+		stream, err := c.WatchClock(ctx, opts)
+		return watchStream(ctx, c, stream, err, func(x *api.Clock) []string {
+			return []string{fmt.Sprintf("%02d:%02d", x.GetHours(), x.GetMinutes()), x.GetPeriod().String()}
+		})
+	default:
+		return fmt.Errorf("unknown domain '%s'", args[0])
+	}
+}
+
+// watchStream prints all messages received from the given stream,
+// until the given context is cancelled.
+func watchStream[T any, PT interface {
+	*T
+	proto.Message
+}](ctx context.Context, c *client, stream interface{ Recv() (PT, error) }, err error, columns func(PT) []string) error {
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				// Stopped by user
+				return nil
+			}
+			return err
+		}
+		if c.json {
+			encoded, err := c.marshaler.MarshalToString(msg)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(c.out, encoded)
+		} else {
+			fmt.Fprintf(c.out, "%s  %s\n", time.Now().Format("15:04:05"), strings.Join(columns(msg), "  "))
+		}
+	}
+}
+
+// listWorkers returns all local workers known by the manager.
+func (c *client) listWorkers(ctx context.Context) ([]api.LocalWorker, error) {
+	stream, err := c.ListWorkers(ctx, &api.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	var result []api.LocalWorker
+	for {
+		lw, err := stream.Recv()
+		if err == io.EOF {
+			return result, nil
+		} else if err != nil {
+			return nil, err
+		}
+		result = append(result, *lw)
+	}
+}
+
+// listDivergentObjects fetches all objects of which the actual state
+// differs from the requested state.
+func (c *client) listDivergentObjects(ctx context.Context) ([]control.DivergentObject, error) {
+	stream, err := c.ListDivergentObjects(ctx, &api.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	var result []control.DivergentObject
+	for {
+		x, err := stream.Recv()
+		if err == io.EOF {
+			return result, nil
+		} else if err != nil {
+			return nil, err
+		}
+		result = append(result, *x)
+	}
+}
+
+// printMessage prints the given message as indented JSON.
+func (c *client) printMessage(msg proto.Message) error {
+	m := c.marshaler
+	m.Indent = "  "
+	encoded, err := m.MarshalToString(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.out, encoded)
+	return err
+}
+
+// printMessages prints the given messages as an indented JSON array.
+func printMessages[T any, PT interface {
+	*T
+	proto.Message
+}](c *client, messages []T) error {
+	list := make([]json.RawMessage, 0, len(messages))
+	for i := range messages {
+		encoded, err := c.marshaler.MarshalToString(PT(&messages[i]))
+		if err != nil {
+			return err
+		}
+		list = append(list, json.RawMessage(encoded))
+	}
+	encoded, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.out, string(encoded))
+	return err
+}
+
+// formatState returns a compact text representation of the given state
+// or "-" if it is not set.
+func formatState[PT interface {
+	comparable
+	proto.Message
+}](x PT) string {
+	var zero PT
+	if x == zero {
+		return "-"
+	}
+	if s := strings.TrimSpace(proto.CompactTextString(x)); s != "" {
+		return s
+	}
+	return "{}"
+}
+
+// formatUptime returns the uptime of the given local worker.
+func formatUptime(info *api.LocalWorkerInfo) string {
+	if info == nil {
+		return "-"
+	}
+	return (time.Duration(info.GetUptime()) * time.Second).String()
+}
+
+// configStatus returns a description of the state of the configuration
+// of the given local worker.
+func configStatus(lw api.LocalWorker) string {
+	requested := lw.GetRequest().GetHash()
+	switch {
+	case requested == "":
+		return "none"
+	case requested == lw.GetActual().GetConfigHash():
+		return "up-to-date"
+	default:
+		return "outdated"
+	}
+}
@@ -6,15 +6,18 @@ replace github.com/coreos/go-systemd => github.com/coreos/go-systemd v0.0.0-2019
 
 require (
 	github.com/binkynet/BinkyNet v1.13.0
+	github.com/fsnotify/fsnotify v1.7.0
+	github.com/golang/protobuf v1.5.4
+	github.com/gorilla/websocket v1.5.0
 	github.com/grpc-ecosystem/go-grpc-middleware v1.4.0
 	github.com/grpc-ecosystem/go-grpc-prometheus v1.2.0
-	github.com/mattn/go-pubsub v0.0.0-20160821075316-7a151c7747cd
 	github.com/mochi-mqtt/server/v2 v2.6.5
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/client_golang v1.19.1
 	github.com/pulcy/go-terminate v0.0.0-20160630075856-d486fe7ee814
 	github.com/rs/zerolog v1.18.0
 	github.com/spf13/pflag v1.0.5
+	go.etcd.io/bbolt v1.3.8
 	golang.org/x/sync v0.3.0
 	google.golang.org/grpc v1.29.1
 	gopkg.in/yaml.v2 v2.4.0
@@ -25,8 +28,6 @@ require (
 	github.com/cenkalti/backoff v2.2.1+incompatible // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/gogo/protobuf v1.3.2 // indirect
-	github.com/golang/protobuf v1.5.4 // indirect
-	github.com/gorilla/websocket v1.5.0 // indirect
 	github.com/grandcat/zeroconf v1.0.0 // indirect
 	github.com/juju/errgo v0.0.0-20140925100237-08cceb5d0b53 // indirect
 	github.com/miekg/dns v1.1.27 // indirect
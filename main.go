@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	api "github.com/binkynet/BinkyNet/apis/v1"
 	"github.com/pkg/errors"
@@ -27,13 +28,19 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/binkynet/NetManager/service"
+	"github.com/binkynet/NetManager/service/config"
+	"github.com/binkynet/NetManager/service/gateway"
 	"github.com/binkynet/NetManager/service/manager"
 	"github.com/binkynet/NetManager/service/server"
+	"github.com/binkynet/NetManager/service/state"
+	"github.com/binkynet/NetManager/service/util"
 )
 
 const (
-	projectName     = "BinkyNet Network Manager"
-	defaultGrpcPort = 8823
+	projectName        = "BinkyNet Network Manager"
+	defaultGrpcPort    = 8823
+	defaultHTTPPort    = 8824
+	defaultGatewayPort = 8825
 )
 
 var (
@@ -42,15 +49,62 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && isClientCommand(os.Args[1]) {
+		if err := runClient(os.Args[1:]); err != nil {
+			Exitf("%v\n", err)
+		}
+		return
+	}
+
 	var levelFlag string
 	var registryFolder string
+	var layoutFile string
+	var useGit bool
+	var gitRef string
 	var serverHost string
 	var grpcPort int
+	var httpPort int
+	var gatewayHost string
+	var gatewayPort int
+	var tlsCertFile, tlsKeyFile, tlsAutoFolder string
+	var mqttConf manager.MQTTConfig
+	var lwTLSConf util.ClientTLSConfig
+	var lwStaleTimeout, lwOfflineTimeout, lwEvictTimeout, lwRequestTimeout time.Duration
+	var lwQueueSize int
+	var stateFile string
 
 	pflag.StringVarP(&levelFlag, "level", "l", "debug", "Set log level")
 	pflag.StringVar(&registryFolder, "folder", "./examples", "Folder containing worker configurations")
+	pflag.BoolVar(&useGit, "git", false, "Read worker configurations from the git repository containing --folder")
+	pflag.StringVar(&gitRef, "git-ref", "", "Git ref to read worker configurations from (implies --git, defaults to working tree)")
+	pflag.StringVar(&layoutFile, "layout", "", "Layout file containing the configuration of all modules (overrides --folder)")
 	pflag.StringVar(&serverHost, "host", "0.0.0.0", "Host the server is listening on")
 	pflag.IntVar(&grpcPort, "port", defaultGrpcPort, "Port the server is listening on")
+	pflag.IntVar(&httpPort, "http-port", defaultHTTPPort, "Port the metrics & health server is listening on (0 to disable)")
+	pflag.StringVar(&gatewayHost, "gateway-host", "127.0.0.1", "Host the REST/JSON & WebSocket gateway is listening on")
+	pflag.IntVar(&gatewayPort, "gateway-port", defaultGatewayPort, "Port the REST/JSON & WebSocket gateway is listening on (0 to disable)")
+	pflag.StringVar(&tlsCertFile, "tls-cert", "", "Certificate file of the server (enables TLS)")
+	pflag.StringVar(&tlsKeyFile, "tls-key", "", "Key file of the server (enables TLS)")
+	pflag.StringVar(&tlsAutoFolder, "tls-auto", "", "Folder to generate a self-signed CA & server certificate in (enables TLS, ignored when --tls-cert is set)")
+	pflag.StringVar(&stateFile, "state", "", "File to persist requested states in (empty keeps them in memory only)")
+	pflag.StringVar(&lwTLSConf.CAFile, "lw-tls-ca", "", "CA bundle used to verify secure local workers (defaults to system roots)")
+	pflag.StringVar(&lwTLSConf.CertFile, "lw-tls-cert", "", "Client certificate file used to dial secure local workers")
+	pflag.StringVar(&lwTLSConf.KeyFile, "lw-tls-key", "", "Client key file used to dial secure local workers")
+	pflag.DurationVar(&lwStaleTimeout, "lw-stale-timeout", manager.DefaultLocalWorkerStaleTimeout, "Time after which a silent local worker is considered stale")
+	pflag.DurationVar(&lwOfflineTimeout, "lw-offline-timeout", manager.DefaultLocalWorkerOfflineTimeout, "Time after which a silent local worker is considered offline (and no longer sent requests)")
+	pflag.DurationVar(&lwEvictTimeout, "lw-evict-timeout", manager.DefaultLocalWorkerEvictTimeout, "Time after which a silent local worker is forgotten")
+	pflag.DurationVar(&lwRequestTimeout, "lw-request-timeout", manager.DefaultLocalWorkerRequestTimeout, "Maximum time a single request to a local worker may take")
+	pflag.IntVar(&lwQueueSize, "lw-queue-size", manager.DefaultLocalWorkerQueueSize, "Maximum number of requests waiting to be sent to a single local worker")
+	pflag.StringVar(&mqttConf.Address, "mqtt-address", manager.DefaultMQTTAddress, "Address of the MQTT listener (empty to disable)")
+	pflag.StringVar(&mqttConf.TLSAddress, "mqtt-tls-address", "", "Address of the MQTT TLS listener (empty to disable)")
+	pflag.StringVar(&mqttConf.TLSCertFile, "mqtt-tls-cert", "", "Certificate file of the MQTT TLS & websocket listeners")
+	pflag.StringVar(&mqttConf.TLSKeyFile, "mqtt-tls-key", "", "Key file of the MQTT TLS & websocket listeners")
+	pflag.StringVar(&mqttConf.WebsocketAddress, "mqtt-websocket-address", "", "Address of the MQTT websocket listener (empty to disable)")
+	pflag.StringVar(&mqttConf.CredentialsFile, "mqtt-credentials", "", "File containing MQTT credentials (empty allows all clients)")
+	pflag.StringVar(&mqttConf.TopicPrefix, "mqtt-topic-prefix", manager.DefaultMQTTTopicPrefix, "Prefix of MQTT topics of local workers")
+	pflag.BoolVar(&mqttConf.Bridge, "mqtt-bridge", false, "Publish actual states & accept requests on MQTT topics (requires --mqtt-credentials)")
+	pflag.BoolVar(&mqttConf.HomeAssistant, "mqtt-homeassistant", false, "Publish Home Assistant discovery messages (requires --mqtt-bridge)")
+	pflag.StringVar(&mqttConf.HomeAssistantPrefix, "mqtt-homeassistant-prefix", manager.DefaultHomeAssistantPrefix, "Discovery prefix of Home Assistant")
 	pflag.Parse()
 
 	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
@@ -64,10 +118,42 @@ func main() {
 
 	// Prepare local worker registry
 	reconfigureQueue := make(chan string, 64)
+	var registry config.Registry
+	var err error
+	if layoutFile != "" {
+		registry, err = config.NewLayoutRegistry(ctx, logger, layoutFile, reconfigureQueue)
+	} else if useGit || gitRef != "" {
+		registry, err = config.NewGitRegistry(ctx, logger, registryFolder, gitRef, reconfigureQueue)
+	} else {
+		registry, err = config.NewFileRegistry(ctx, logger, registryFolder, reconfigureQueue)
+	}
+	if err != nil {
+		Exitf("Failed to initialize local worker registry: %v\n", err)
+	}
+
+	// Prepare state store
+	var stateStore *state.Store
+	if stateFile != "" {
+		stateStore, err = state.Open(stateFile)
+		if err != nil {
+			Exitf("Failed to open state store: %v\n", err)
+		}
+		defer stateStore.Close()
+	}
 
 	// Prepare manager core
-	mgr, err := manager.New(manager.Dependencies{
+	mgr, err := manager.New(manager.Config{
+		MQTT:                      mqttConf,
+		LocalWorkerTLS:            lwTLSConf,
+		LocalWorkerStaleTimeout:   lwStaleTimeout,
+		LocalWorkerOfflineTimeout: lwOfflineTimeout,
+		LocalWorkerEvictTimeout:   lwEvictTimeout,
+		LocalWorkerRequestTimeout: lwRequestTimeout,
+		LocalWorkerQueueSize:      lwQueueSize,
+	}, manager.Dependencies{
 		Log:              logger,
+		ConfigRegistry:   registry,
+		StateStore:       stateStore,
 		ReconfigureQueue: reconfigureQueue,
 	})
 	if err != nil {
@@ -87,18 +173,41 @@ func main() {
 
 	// Prepare network server
 	server, err := server.NewServer(server.Config{
-		Host:     serverHost,
-		GRPCPort: grpcPort,
+		Host:          serverHost,
+		GRPCPort:      grpcPort,
+		HTTPPort:      httpPort,
+		TLSCertFile:   tlsCertFile,
+		TLSKeyFile:    tlsKeyFile,
+		TLSAutoFolder: tlsAutoFolder,
 	}, svc, logger)
 	if err != nil {
 		Exitf("Failed to initialize Server: %v\n", err)
 	}
 
+	// Prepare REST/JSON & WebSocket gateway
+	var gw gateway.Gateway
+	if gatewayPort != 0 {
+		gw, err = gateway.New(gateway.Config{
+			Host:      gatewayHost,
+			Port:      gatewayPort,
+			TLSConfig: server.TLSConfig(),
+		}, gateway.Dependencies{
+			Log:     logger,
+			Manager: mgr,
+		})
+		if err != nil {
+			Exitf("Failed to initialize Gateway: %v\n", err)
+		}
+	}
+
 	fmt.Printf("Starting %s (version %s build %s)\n", projectName, projectVersion, projectBuild)
 	g, ctx := errgroup.WithContext(ctx)
 	ctx = api.WithServiceInfoHost(ctx, serverHost)
 	g.Go(func() error { return mgr.Run(ctx) })
 	g.Go(func() error { return server.Run(ctx) })
+	if gw != nil {
+		g.Go(func() error { return gw.Run(ctx) })
+	}
 	if err := g.Wait(); err != nil && errors.Cause(err) != context.Canceled {
 		Exitf("Failed to run services: %#v\n", err)
 	}
@@ -70,3 +70,16 @@ func MustRegisterGaugeVec(subSubsystem, name, help string, labels ...string) *pr
 	prometheus.MustRegister(c)
 	return c
 }
+
+// MustRegisterHistogramVec creates and registers a histogramVec with given subSystem & name.
+func MustRegisterHistogramVec(subSubsystem, name, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	c := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subSubsystem,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labels)
+	prometheus.MustRegister(c)
+	return c
+}
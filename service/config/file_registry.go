@@ -19,26 +19,35 @@ package config
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	model "github.com/binkynet/BinkyNet/apis/v1"
-	yaml "gopkg.in/yaml.v2"
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+
+	"github.com/binkynet/NetManager/service/config/validator"
 )
 
 type registryEntry struct {
 	model.LocalWorkerConfig
 	modTime time.Time
+	// Templates used by the configuration
+	templates *templateLoader
+	// Error encountered while reading or validating the configuration (if any)
+	err error
 }
 
 // NewFileRegistry create a new Registry implementation backed by a filesystem.
-func NewFileRegistry(ctx context.Context, folder string, reconfigureQueue chan string) (Registry, error) {
+func NewFileRegistry(ctx context.Context, log zerolog.Logger, folder string, reconfigureQueue chan string) (Registry, error) {
 	r := &registry{
+		log:              log.With().Str("component", "file-registry").Logger(),
 		folder:           folder,
 		configs:          make(map[string]registryEntry),
 		reconfigureQueue: reconfigureQueue,
@@ -49,6 +58,7 @@ func NewFileRegistry(ctx context.Context, folder string, reconfigureQueue chan s
 
 type registry struct {
 	mutex            sync.Mutex
+	log              zerolog.Logger
 	folder           string
 	configs          map[string]registryEntry
 	reconfigureQueue chan string
@@ -60,92 +70,169 @@ func (r *registry) Get(id string) (model.LocalWorkerConfig, error) {
 	defer r.mutex.Unlock()
 
 	if x, found := r.configs[id]; found {
-		return x.LocalWorkerConfig, nil
+		return x.LocalWorkerConfig, x.err
 	}
 
 	// Read from disk
-	conf, modTime, err := readWorkerConfiguration(r.folder, id)
+	entry, err := readWorkerConfiguration(r.folder, id)
 	if err != nil {
+		if !entry.modTime.IsZero() {
+			// File exists but is invalid, remember it so we notice when it is fixed.
+			r.configs[id] = entry
+		}
 		return model.LocalWorkerConfig{}, err
 	}
 
-	fmt.Printf("Read %+v\n", conf)
-
 	// Save config
-	r.configs[id] = registryEntry{
-		LocalWorkerConfig: conf,
-		modTime:           modTime,
-	}
+	r.configs[id] = entry
 
-	return conf, nil
+	return entry.LocalWorkerConfig, nil
 }
 
 // runMaintenance keeps maintaining the registry until the given context is canceled.
+// The folder is watched for changes. If that is not possible, the registry falls
+// back to polling.
 func (r *registry) runMaintenance(ctx context.Context) {
-	for {
-		// Cleanup once
-		r.removeChangedConfigs()
-
-		select {
-		case <-time.After(time.Second * 5):
-			// Continue
-		case <-ctx.Done():
-			return
+	pending := make(map[string]struct{})
+	templatesChanged := false
+	folderWatcher{
+		log:    r.log,
+		folder: r.folder,
+		handleEvent: func(evt fsnotify.Event) bool {
+			id, isTemplate := r.handleEvent(evt)
+			if id != "" {
+				pending[id] = struct{}{}
+			}
+			templatesChanged = templatesChanged || isTemplate
+			return id != "" || isTemplate
+		},
+		applyChanges: func(ctx context.Context) {
+			r.removeConfigs(ctx, pending, templatesChanged)
+			pending = make(map[string]struct{})
+			templatesChanged = false
+		},
+		checkChanges: r.removeChangedConfigs,
+	}.run(ctx)
+}
+
+// handleEvent inspects the given filesystem event.
+// Returns the ID of the worker whose configuration file is affected (if any)
+// and true if a template is affected.
+// Creates, writes, renames & removes are all treated as a change, so atomic saves
+// (write to a temporary file & rename) are covered by the event of the target file.
+func (r *registry) handleEvent(evt fsnotify.Event) (string, bool) {
+	folder := filepath.Clean(r.folder)
+	templatesFolder := filepath.Join(folder, templatesFolderName)
+	name := filepath.Clean(evt.Name)
+	switch {
+	case name == templatesFolder, filepath.Dir(name) == templatesFolder:
+		return "", true
+	case filepath.Dir(name) == folder:
+		if ext := filepath.Ext(name); isConfigExtension(ext) {
+			return strings.TrimSuffix(filepath.Base(name), ext), false
+		}
+	}
+	return "", false
+}
+
+// removeConfigs removes the cached configs of the workers with given IDs
+// (and of all workers if templates have changed) and queues them for reconfiguration.
+// IDs that are not cached are queued as well, so new configuration files are
+// noticed for workers that are already connected.
+func (r *registry) removeConfigs(ctx context.Context, ids map[string]struct{}, templatesChanged bool) {
+	changed := make(map[string]struct{}, len(ids))
+	r.mutex.Lock()
+	for id := range ids {
+		changed[id] = struct{}{}
+	}
+	if templatesChanged {
+		for id := range r.configs {
+			changed[id] = struct{}{}
 		}
 	}
+	for id := range changed {
+		delete(r.configs, id)
+	}
+	r.mutex.Unlock()
+
+	// Notify outside the lock, since the receiver is likely to call Get.
+	sorted := make([]string, 0, len(changed))
+	for id := range changed {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	queueReconfigure(ctx, r.reconfigureQueue, sorted)
 }
 
 // removeChangedConfigs reads the modification time of the cache worker configs
 // and removes those that have a different modification time or are no longer
 // found.
-func (r *registry) removeChangedConfigs() {
+func (r *registry) removeChangedConfigs(ctx context.Context) {
+	var changed []string
 	r.mutex.Lock()
-	defer r.mutex.Unlock()
-
 	for id, entry := range r.configs {
-		_, modTime, err := readWorkerConfiguration(r.folder, id)
-		if err != nil || modTime != entry.modTime {
+		_, info, err := findWorkerConfiguration(r.folder, id)
+		if err != nil || info.ModTime() != entry.modTime || entry.templates.changed() {
 			delete(r.configs, id)
-			if r.reconfigureQueue != nil {
-				r.reconfigureQueue <- id
-			}
+			changed = append(changed, id)
 		}
 	}
+	r.mutex.Unlock()
+
+	// Notify outside the lock, since the receiver is likely to call Get.
+	queueReconfigure(ctx, r.reconfigureQueue, changed)
 }
 
-// readWorkerConfiguration tries to read the content of a configuration with given ID.
-func readWorkerConfiguration(folder, id string) (model.LocalWorkerConfig, time.Time, error) {
-	// Read file (yaml)
-	filePath := filepath.Join(folder, id+".yaml")
-	info, err := os.Stat(filePath)
-	if err == nil {
-		content, err := ioutil.ReadFile(filePath)
-		if err != nil {
-			return model.LocalWorkerConfig{}, time.Time{}, err
+// findWorkerConfiguration looks for a configuration file for the worker with given ID.
+// Returns: path, file info, error
+func findWorkerConfiguration(folder, id string) (string, os.FileInfo, error) {
+	var lastErr error
+	for _, ext := range configExtensions {
+		filePath := filepath.Join(folder, id+ext)
+		info, err := os.Stat(filePath)
+		if err == nil {
+			return filePath, info, nil
 		}
-		// Parse file
-		var conf model.LocalWorkerConfig
-		if err := yaml.Unmarshal(content, &conf); err != nil {
-			return model.LocalWorkerConfig{}, time.Time{}, err
-		}
-		return conf, info.ModTime(), nil
+		lastErr = err
 	}
+	return "", nil, lastErr
+}
 
-	// Read file (json)
-	filePath = filepath.Join(folder, id+".json")
-	info, err = os.Stat(filePath)
-	if err == nil {
-		content, err := ioutil.ReadFile(filePath)
-		if err != nil {
-			return model.LocalWorkerConfig{}, time.Time{}, err
-		}
+// readWorkerConfiguration tries to read the content of a configuration with given ID.
+// If the configuration file exists, the returned entry contains its modification time
+// and the used templates, even when the content is invalid.
+func readWorkerConfiguration(folder, id string) (registryEntry, error) {
+	filePath, info, err := findWorkerConfiguration(folder, id)
+	if err != nil {
+		return registryEntry{}, err
+	}
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return registryEntry{}, err
+	}
+	entry := registryEntry{
+		modTime:   info.ModTime(),
+		templates: newTemplateLoader(filepath.Join(folder, templatesFolderName)),
+	}
+	return entry.parse(filePath, content)
+}
 
-		// Parse file
-		var conf model.LocalWorkerConfig
-		if err := json.Unmarshal(content, &conf); err != nil {
-			return model.LocalWorkerConfig{}, time.Time{}, err
-		}
-		return conf, info.ModTime(), nil
+// parse parses & validates the given content of the configuration file at given path.
+// Returns a copy of the entry with the resulting configuration, or with the error
+// that was encountered.
+func (entry registryEntry) parse(filePath string, content []byte) (registryEntry, error) {
+	// Parse file
+	conf, err := parseWorkerConfiguration(content, isYAMLExtension(filepath.Ext(filePath)), entry.templates)
+	if err != nil {
+		entry.err = fmt.Errorf("failed to parse %s: %w", filePath, err)
+		return entry, entry.err
+	}
+
+	// Validate content
+	if err := validator.Validate(conf); err != nil {
+		entry.err = fmt.Errorf("invalid configuration in %s: %w", filePath, err)
+		return entry, entry.err
 	}
-	return model.LocalWorkerConfig{}, time.Time{}, err
+	entry.LocalWorkerConfig = conf
+	return entry, nil
 }
@@ -0,0 +1,260 @@
+// Copyright 2024 Ewout Prangsma
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author Ewout Prangsma
+//
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	model "github.com/binkynet/BinkyNet/apis/v1"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Local worker configuration files come in 2 shapes.
+//
+// List-style files contain devices, objects & connections as arrays
+// where each entry has its own `id` (or `key` for connections):
+//
+//	"devices": [ { "id": "io1", "type": "mcp23017", "address": "0x20" } ]
+//
+// Map-style files contain devices, objects & connections as maps
+// keyed by their ID (or key for connections):
+//
+//	"devices": { "io1": { "type": "mcp23017", "address": "0x20" } }
+//
+// Both shapes are accepted in YAML and JSON and normalized into
+// a model.LocalWorkerConfig.
+
+// parseWorkerConfiguration parses the given content (YAML or JSON) into a local
+// worker configuration.
+// Includes are expanded using the given template loader.
+func parseWorkerConfiguration(content []byte, isYAML bool, templates *templateLoader) (model.LocalWorkerConfig, error) {
+	raw, err := unmarshalRawConfig(content, isYAML)
+	if err != nil {
+		return model.LocalWorkerConfig{}, err
+	}
+	if raw, err = templates.expandIncludes(raw, 0); err != nil {
+		return model.LocalWorkerConfig{}, err
+	}
+	return raw.toModel(), nil
+}
+
+var (
+	// Extensions of configuration, layout & template files, in order of preference
+	configExtensions = []string{".yaml", ".yml", ".json"}
+)
+
+// isConfigExtension returns true if the given file extension is one of configExtensions.
+func isConfigExtension(ext string) bool {
+	for _, x := range configExtensions {
+		if ext == x {
+			return true
+		}
+	}
+	return false
+}
+
+// isYAMLExtension returns true if files with the given extension contain YAML.
+func isYAMLExtension(ext string) bool {
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// unmarshalRawConfig parses the given content (YAML or JSON) without
+// expanding includes.
+func unmarshalRawConfig(content []byte, isYAML bool) (rawConfig, error) {
+	var raw rawConfig
+	if isYAML {
+		if err := yaml.Unmarshal(content, &raw); err != nil {
+			return rawConfig{}, err
+		}
+	} else {
+		if err := json.Unmarshal(content, &raw); err != nil {
+			return rawConfig{}, err
+		}
+	}
+	return raw, nil
+}
+
+type rawConfig struct {
+	Alias   string               `json:"alias" yaml:"alias"`
+	Include []rawInclude         `json:"include" yaml:"include"`
+	Devices keyedList[rawDevice] `json:"devices" yaml:"devices"`
+	Objects keyedList[rawObject] `json:"objects" yaml:"objects"`
+}
+
+type rawDevice struct {
+	ID      string `json:"id" yaml:"id"`
+	Type    string `json:"type" yaml:"type"`
+	Address string `json:"address" yaml:"address"`
+}
+
+type rawObject struct {
+	ID            string                   `json:"id" yaml:"id"`
+	Type          string                   `json:"type" yaml:"type"`
+	Connections   keyedList[rawConnection] `json:"connections" yaml:"connections"`
+	Configuration map[string]string        `json:"configuration" yaml:"configuration"`
+}
+
+type rawConnection struct {
+	Key           string            `json:"key" yaml:"key"`
+	Pins          []rawPin          `json:"pins" yaml:"pins"`
+	Configuration map[string]string `json:"configuration" yaml:"configuration"`
+	// Config is a short alias for Configuration
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+type rawPin struct {
+	Device string `json:"device" yaml:"device"`
+	// DeviceID is the name used by YAML files created for earlier versions
+	DeviceID string `json:"-" yaml:"deviceid"`
+	Index    uint   `json:"index" yaml:"index"`
+}
+
+func (c rawConfig) toModel() model.LocalWorkerConfig {
+	result := model.LocalWorkerConfig{
+		Alias: c.Alias,
+	}
+	for _, d := range c.Devices {
+		result.Devices = append(result.Devices, &model.Device{
+			Id:      model.DeviceID(d.ID),
+			Type:    model.DeviceType(d.Type),
+			Address: d.Address,
+		})
+	}
+	for _, o := range c.Objects {
+		obj := &model.Object{
+			Id:   model.ObjectID(o.ID),
+			Type: model.ObjectType(o.Type),
+		}
+		for _, conn := range o.Connections {
+			obj.Connections = append(obj.Connections, conn.toModel())
+		}
+		if len(o.Configuration) > 0 {
+			obj.Configuration = make(map[model.ObjectConfigKey]string)
+			for k, v := range o.Configuration {
+				obj.Configuration[model.ObjectConfigKey(k)] = v
+			}
+		}
+		result.Objects = append(result.Objects, obj)
+	}
+	return result
+}
+
+func (c rawConnection) toModel() *model.Connection {
+	result := &model.Connection{
+		Key: model.ConnectionName(c.Key),
+	}
+	for _, p := range c.Pins {
+		deviceID := p.Device
+		if deviceID == "" {
+			deviceID = p.DeviceID
+		}
+		result.Pins = append(result.Pins, &model.DevicePin{
+			DeviceId: model.DeviceID(deviceID),
+			Index:    model.DeviceIndex(p.Index),
+		})
+	}
+	for _, m := range []map[string]string{c.Config, c.Configuration} {
+		for k, v := range m {
+			if result.Configuration == nil {
+				result.Configuration = make(map[model.ConfigKey]string)
+			}
+			result.Configuration[model.ConfigKey(k)] = v
+		}
+	}
+	return result
+}
+
+func (d *rawDevice) key() *string     { return &d.ID }
+func (o *rawObject) key() *string     { return &o.ID }
+func (c *rawConnection) key() *string { return &c.Key }
+
+// keyedList is a list of entries that can be specified as an array
+// or as a map keyed by the identifier of each entry.
+type keyedList[T any] []T
+
+// keyed is implemented by entries of a keyedList.
+type keyed interface {
+	key() *string
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *keyedList[T]) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var m map[string]T
+		if err := json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		return l.setFromMap(m)
+	}
+	var list []T
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *keyedList[T]) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var probe interface{}
+	if err := unmarshal(&probe); err != nil {
+		return err
+	}
+	if _, isMap := probe.(map[interface{}]interface{}); isMap {
+		var m map[string]T
+		if err := unmarshal(&m); err != nil {
+			return err
+		}
+		return l.setFromMap(m)
+	}
+	var list []T
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// setFromMap fills the list with the entries of the given map, using the map
+// keys as identifiers. Entries are sorted by key to yield a stable order.
+func (l *keyedList[T]) setFromMap(m map[string]T) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := make([]T, 0, len(keys))
+	for _, k := range keys {
+		entry := m[k]
+		kp, ok := any(&entry).(keyed)
+		if !ok {
+			return fmt.Errorf("entries of type %T cannot be keyed", entry)
+		}
+		if id := kp.key(); *id == "" {
+			*id = k
+		} else if *id != k {
+			return fmt.Errorf("entry '%s' has a conflicting identifier '%s'", k, *id)
+		}
+		list = append(list, entry)
+	}
+	*l = list
+	return nil
+}
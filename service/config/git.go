@@ -0,0 +1,148 @@
+// Copyright 2024 Ewout Prangsma
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author Ewout Prangsma
+//
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Maximum duration of a single git command
+	gitCommandTimeout = time.Second * 30
+)
+
+// gitRepo is a minimal wrapper around the git command line tool.
+type gitRepo struct {
+	// Top level folder of the repository
+	root string
+}
+
+// openGitRepo opens the git repository containing the given folder.
+// Returns: repository, path of folder relative to the repository root, error
+func openGitRepo(folder string) (*gitRepo, string, error) {
+	g := &gitRepo{root: folder}
+	root, err := g.run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, "", fmt.Errorf("%s is not inside a git repository: %w", folder, err)
+	}
+	prefix, err := g.run("rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, "", err
+	}
+	return &gitRepo{root: strings.TrimSpace(root)}, strings.TrimSuffix(strings.TrimSpace(prefix), "/"), nil
+}
+
+// run the git command with given arguments in the repository and returns its output.
+func (g *gitRepo) run(args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitCommandTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", g.root}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// resolve returns the commit hash the given ref points to.
+func (g *gitRepo) resolve(ref string) (string, error) {
+	out, err := g.run("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("%w '%s': %w", ErrUnknownRevision, ref, err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// show returns the content of the file at given path (relative to the repository root)
+// in the given commit.
+// Returns an error wrapping fs.ErrNotExist if the file does not exist in that commit.
+func (g *gitRepo) show(commit, path string) ([]byte, error) {
+	object := commit + ":" + path
+	if _, err := g.run("cat-file", "-e", object); err != nil {
+		return nil, fmt.Errorf("%s: %w", object, fs.ErrNotExist)
+	}
+	out, err := g.run("cat-file", "blob", object)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// changedFiles returns the paths (relative to the repository root) of all files
+// below the given folder that differ between the 2 given commits.
+func (g *gitRepo) changedFiles(from, to, folder string) ([]string, error) {
+	out, err := g.run("diff", "--name-only", from, to, "--", pathspec(folder))
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// hasLocalChanges returns true if any of the given paths has uncommitted changes.
+func (g *gitRepo) hasLocalChanges(paths ...string) (bool, error) {
+	out, err := g.run(append([]string{"status", "--porcelain", "--"}, paths...)...)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// log returns the commits reachable from the given commit that touch any
+// of the given paths. Newest commits come first.
+func (g *gitRepo) log(commit string, paths ...string) ([]Revision, error) {
+	args := append([]string{"log", "--format=%H%x1f%ct%x1f%an%x1f%s", commit, "--"}, paths...)
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var result []Revision
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		ts, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit time '%s': %w", fields[1], err)
+		}
+		result = append(result, Revision{
+			Hash:    fields[0],
+			Time:    time.Unix(ts, 0),
+			Author:  fields[2],
+			Subject: fields[3],
+		})
+	}
+	return result, nil
+}
+
+// pathspec returns a pathspec for the given folder (relative to the repository root).
+func pathspec(folder string) string {
+	if folder == "" {
+		return "."
+	}
+	return folder
+}
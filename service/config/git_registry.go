@@ -0,0 +1,334 @@
+// Copyright 2024 Ewout Prangsma
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author Ewout Prangsma
+//
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	model "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/rs/zerolog"
+)
+
+// NewGitRegistry creates a new Registry implementation backed by a folder
+// in a git repository.
+// If ref is empty, configurations are read from the working tree,
+// otherwise they are read from the commit that ref points to.
+// Local workers that are rolled back stay on their revision until they are
+// rolled forward again, or the manager restarts.
+func NewGitRegistry(ctx context.Context, log zerolog.Logger, folder, ref string, reconfigureQueue chan string) (HistoryRegistry, error) {
+	repo, prefix, err := openGitRepo(folder)
+	if err != nil {
+		return nil, err
+	}
+	r := &gitRegistry{
+		log:              log.With().Str("component", "git-registry").Logger(),
+		repo:             repo,
+		folder:           folder,
+		prefix:           prefix,
+		ref:              ref,
+		configs:          make(map[string]registryEntry),
+		pinned:           make(map[string]string),
+		reconfigureQueue: reconfigureQueue,
+	}
+	if r.revision, err = repo.resolve(r.headRef()); err != nil {
+		return nil, err
+	}
+	go r.runMaintenance(ctx)
+	return r, nil
+}
+
+type gitRegistry struct {
+	mutex  sync.Mutex
+	log    zerolog.Logger
+	repo   *gitRepo
+	folder string
+	// Path of folder relative to the root of the repository
+	prefix string
+	// Ref to read configurations from (empty for working tree)
+	ref string
+	// Commit that ref (or HEAD for working tree) currently points to
+	revision string
+	configs  map[string]registryEntry
+	// Revision per rolled back worker
+	pinned           map[string]string
+	reconfigureQueue chan string
+}
+
+// Get returns the configuration for a worker with given ID.
+func (r *gitRegistry) Get(id string) (model.LocalWorkerConfig, error) {
+	r.mutex.Lock()
+	if x, found := r.configs[id]; found {
+		r.mutex.Unlock()
+		return x.LocalWorkerConfig, x.err
+	}
+	rev, pinned := r.sourceRevision(id)
+	r.mutex.Unlock()
+
+	// Read outside the lock, since reading from git runs a subprocess
+	var entry registryEntry
+	var err error
+	if !pinned && r.ref == "" {
+		entry, err = readWorkerConfiguration(r.folder, id)
+	} else {
+		entry, err = r.readWorkerConfigurationAt(rev, id)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	// Do not cache the configuration when the revision changed while reading.
+	// Invalid files are cached as well, so we notice when they are fixed.
+	if current, _ := r.sourceRevision(id); current == rev && !errors.Is(err, fs.ErrNotExist) {
+		r.configs[id] = entry
+	}
+	if err != nil {
+		return model.LocalWorkerConfig{}, err
+	}
+	return entry.LocalWorkerConfig, nil
+}
+
+// sourceRevision returns the revision the configuration of the worker with given ID
+// is read from, and true if the worker is rolled back to that revision.
+// Must be called while holding the mutex.
+func (r *gitRegistry) sourceRevision(id string) (string, bool) {
+	if rev, found := r.pinned[id]; found {
+		return rev, true
+	}
+	return r.revision, false
+}
+
+// History returns the revisions of the configuration of the worker with given ID.
+// Newest revisions come first.
+func (r *gitRegistry) History(id string) ([]Revision, error) {
+	r.mutex.Lock()
+	revision := r.revision
+	active, pinned := r.pinned[id]
+	r.mutex.Unlock()
+
+	paths := r.workerPaths(id)
+	result, err := r.repo.log(revision, paths...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		if _, _, err := findWorkerConfiguration(r.folder, id); err == nil && r.ref == "" {
+			// Worker is configured with a file that is not committed yet
+			return result, nil
+		}
+		return nil, fmt.Errorf("no configuration history for worker [%s]: %w", id, fs.ErrNotExist)
+	}
+	if !pinned {
+		if r.ref == "" {
+			if dirty, err := r.repo.hasLocalChanges(paths...); err != nil {
+				return nil, err
+			} else if dirty {
+				// Worker is configured with uncommitted changes
+				return result, nil
+			}
+		}
+		active = revision
+	}
+	if current, err := r.repo.log(active, paths...); err != nil {
+		return nil, err
+	} else if len(current) > 0 {
+		for i := range result {
+			result[i].Active = result[i].Hash == current[0].Hash
+		}
+	}
+	return result, nil
+}
+
+// Rollback configures the worker with given ID with its configuration
+// at the given revision.
+// If revision is empty, the worker follows the latest configuration again.
+func (r *gitRegistry) Rollback(ctx context.Context, id, revision string) error {
+	if revision == "" {
+		r.mutex.Lock()
+		delete(r.pinned, id)
+		delete(r.configs, id)
+		r.mutex.Unlock()
+	} else {
+		// Read outside the lock, since reading from git runs a subprocess
+		hash, err := r.repo.resolve(revision)
+		if err != nil {
+			return err
+		}
+		entry, err := r.readWorkerConfigurationAt(hash, id)
+		if err != nil {
+			return fmt.Errorf("cannot rollback worker [%s] to %s: %w", id, revision, err)
+		}
+		r.mutex.Lock()
+		r.pinned[id] = hash
+		r.configs[id] = entry
+		r.mutex.Unlock()
+	}
+
+	// Notify outside the lock, since the receiver is likely to call Get.
+	queueReconfigure(ctx, r.reconfigureQueue, []string{id})
+	return nil
+}
+
+// runMaintenance keeps maintaining the registry until the given context is canceled.
+func (r *gitRegistry) runMaintenance(ctx context.Context) {
+	for {
+		// Cleanup once
+		r.removeChangedConfigs(ctx)
+
+		select {
+		case <-time.After(time.Second * 5):
+			// Continue
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// removeChangedConfigs removes the cached configurations of all workers whose
+// configuration changed.
+// When the ref moves to another commit, all workers affected by the files changed
+// between the old & new commit are reconfigured together.
+// In working tree mode, uncommitted changes are detected using the modification
+// time of the configuration files.
+func (r *gitRegistry) removeChangedConfigs(ctx context.Context) {
+	head, err := r.repo.resolve(r.headRef())
+	if err != nil {
+		r.log.Warn().Err(err).Str("ref", r.headRef()).Msg("Failed to resolve ref")
+		return
+	}
+	// The revision is only changed here, so it can be read once.
+	// Changed files are listed outside the lock, since that runs a subprocess.
+	r.mutex.Lock()
+	revision := r.revision
+	r.mutex.Unlock()
+	var files []string
+	var filesErr error
+	if head != revision {
+		files, filesErr = r.repo.changedFiles(revision, head, r.prefix)
+	}
+
+	changed := make(map[string]struct{})
+	r.mutex.Lock()
+	if head != revision {
+		ids, all := r.workersInFiles(files)
+		if filesErr != nil || all {
+			// Cannot tell which workers are affected
+			for id := range r.configs {
+				ids = append(ids, id)
+			}
+		}
+		for _, id := range ids {
+			changed[id] = struct{}{}
+		}
+		r.revision = head
+	}
+	if r.ref == "" {
+		for id, entry := range r.configs {
+			if _, found := r.pinned[id]; found {
+				continue
+			}
+			_, info, err := findWorkerConfiguration(r.folder, id)
+			if err != nil || info.ModTime() != entry.modTime || entry.templates.changed() {
+				changed[id] = struct{}{}
+			}
+		}
+	}
+	ids := make([]string, 0, len(changed))
+	for id := range changed {
+		if _, found := r.pinned[id]; found {
+			// Rolled back workers stay on their revision
+			continue
+		}
+		delete(r.configs, id)
+		ids = append(ids, id)
+	}
+	r.mutex.Unlock()
+
+	// Notify outside the lock, since the receiver is likely to call Get.
+	sort.Strings(ids)
+	queueReconfigure(ctx, r.reconfigureQueue, ids)
+}
+
+// headRef returns the ref that is followed by the registry.
+func (r *gitRegistry) headRef() string {
+	if r.ref == "" {
+		return "HEAD"
+	}
+	return r.ref
+}
+
+// workerPaths returns the possible paths (relative to the repository root)
+// of the configuration file of the worker with given ID.
+func (r *gitRegistry) workerPaths(id string) []string {
+	result := make([]string, 0, len(configExtensions))
+	for _, ext := range configExtensions {
+		result = append(result, path.Join(r.prefix, id+ext))
+	}
+	return result
+}
+
+// workersInFiles returns the IDs of the workers whose configuration is
+// stored in the given files (relative to the repository root).
+// If any of the files is a template, all is set to true.
+func (r *gitRegistry) workersInFiles(files []string) (ids []string, all bool) {
+	templatesFolder := path.Join(r.prefix, templatesFolderName) + "/"
+	for _, f := range files {
+		if strings.HasPrefix(f, templatesFolder) {
+			all = true
+			continue
+		}
+		if path.Dir(f) != path.Clean("./"+r.prefix) {
+			continue
+		}
+		if ext := path.Ext(f); isConfigExtension(ext) {
+			ids = append(ids, strings.TrimSuffix(path.Base(f), ext))
+		}
+	}
+	return ids, all
+}
+
+// readWorkerConfigurationAt tries to read the content of a configuration with
+// given ID from the given commit.
+// If the configuration file exists in that commit, the returned entry contains
+// the used templates, even when the content is invalid.
+func (r *gitRegistry) readWorkerConfigurationAt(commit, id string) (registryEntry, error) {
+	read := func(p string) ([]byte, time.Time, error) {
+		content, err := r.repo.show(commit, p)
+		// Content of a commit never changes
+		return content, time.Time{}, err
+	}
+	for _, p := range r.workerPaths(id) {
+		content, _, err := read(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return registryEntry{}, err
+		}
+		entry := registryEntry{
+			templates: newTemplateLoaderWithReader(path.Join(r.prefix, templatesFolderName), read),
+		}
+		return entry.parse(commit[:7]+":"+p, content)
+	}
+	return registryEntry{}, fmt.Errorf("no configuration for worker [%s] in %s: %w", id, commit[:7], fs.ErrNotExist)
+}
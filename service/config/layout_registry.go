@@ -0,0 +1,312 @@
+// Copyright 2024 Ewout Prangsma
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author Ewout Prangsma
+//
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	model "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/binkynet/NetManager/service/config/validator"
+)
+
+// A layout file describes all modules of a layout in a single document.
+// Every module lists the local workers (by hardware ID) that control it,
+// together with their alias, devices and objects:
+//
+//	modules:
+//	  station:
+//	    workers:
+//	      9381a8f378:
+//	        alias: station-north
+//	        devices: ...
+//	        objects: ...
+//
+// Modules, workers, devices, objects & connections can all be specified
+// as lists (with an `id` field) or as maps keyed by their ID.
+// Workers can include templates from the `templates` folder next to
+// the layout file.
+
+type rawLayout struct {
+	Modules keyedList[rawModule] `json:"modules" yaml:"modules"`
+}
+
+type rawModule struct {
+	ID          string                     `json:"id" yaml:"id"`
+	Description string                     `json:"description" yaml:"description"`
+	Workers     keyedList[rawLayoutWorker] `json:"workers" yaml:"workers"`
+}
+
+type rawLayoutWorker struct {
+	ID        string `json:"id" yaml:"id"`
+	rawConfig `yaml:",inline"`
+}
+
+func (m *rawModule) key() *string       { return &m.ID }
+func (w *rawLayoutWorker) key() *string { return &w.ID }
+
+// NewLayoutRegistry creates a new Registry implementation backed by a single
+// layout file describing all modules and their local workers.
+func NewLayoutRegistry(ctx context.Context, log zerolog.Logger, path string, reconfigureQueue chan string) (Registry, error) {
+	r := &layoutRegistry{
+		log:              log.With().Str("component", "layout-registry").Logger(),
+		path:             path,
+		reconfigureQueue: reconfigureQueue,
+	}
+	workers, modTime, templates, err := readLayout(path)
+	if err != nil {
+		return nil, err
+	}
+	r.workers = workers
+	r.modTime = modTime
+	r.templates = templates
+	go r.runMaintenance(ctx)
+	return r, nil
+}
+
+type layoutRegistry struct {
+	mutex            sync.Mutex
+	log              zerolog.Logger
+	path             string
+	modTime          time.Time
+	templates        *templateLoader
+	workers          map[string]model.LocalWorkerConfig
+	err              error
+	reconfigureQueue chan string
+}
+
+// Get returns the configuration for a worker with given ID.
+func (r *layoutRegistry) Get(id string) (model.LocalWorkerConfig, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.err != nil {
+		return model.LocalWorkerConfig{}, r.err
+	}
+	conf, found := r.workers[id]
+	if !found {
+		return model.LocalWorkerConfig{}, fmt.Errorf("local worker [%s] not found in layout %s", id, r.path)
+	}
+	return *conf.Clone(), nil
+}
+
+// runMaintenance keeps maintaining the registry until the given context is canceled.
+// The folder of the layout file is watched for changes. If that is not possible,
+// the registry falls back to polling.
+func (r *layoutRegistry) runMaintenance(ctx context.Context) {
+	folder := filepath.Dir(r.path)
+	layoutPath := filepath.Clean(r.path)
+	templatesFolder := filepath.Join(folder, templatesFolderName)
+	folderWatcher{
+		log:    r.log,
+		folder: folder,
+		handleEvent: func(evt fsnotify.Event) bool {
+			name := filepath.Clean(evt.Name)
+			return name == layoutPath || name == templatesFolder || filepath.Dir(name) == templatesFolder
+		},
+		applyChanges: r.reloadIfChanged,
+		checkChanges: r.reloadIfChanged,
+	}.run(ctx)
+}
+
+// reloadIfChanged reloads the layout file when its modification time has changed
+// and queues all local workers with a changed configuration for reconfiguration.
+// When the layout has become invalid, all workers are queued so that the
+// problem is reported, while the workers keep their current configuration.
+func (r *layoutRegistry) reloadIfChanged(ctx context.Context) {
+	r.mutex.Lock()
+	info, err := os.Stat(r.path)
+	if err == nil && info.ModTime() == r.modTime && !r.templates.changed() {
+		r.mutex.Unlock()
+		return
+	}
+	workers, modTime, templates, err := readLayout(r.path)
+	changed := make(map[string]struct{})
+	if err != nil || r.err != nil {
+		// Going into or out of an invalid state affects all workers
+		for id := range r.workers {
+			changed[id] = struct{}{}
+		}
+		for id := range workers {
+			changed[id] = struct{}{}
+		}
+	} else {
+		for id, conf := range workers {
+			if old, found := r.workers[id]; !found || !old.Equal(&conf) {
+				changed[id] = struct{}{}
+			}
+		}
+		for id := range r.workers {
+			if _, found := workers[id]; !found {
+				changed[id] = struct{}{}
+			}
+		}
+	}
+	r.modTime = modTime
+	r.templates = templates
+	r.err = err
+	if err == nil {
+		r.workers = workers
+	}
+	r.mutex.Unlock()
+	if err != nil {
+		r.log.Error().Err(err).Str("path", r.path).Msg("Failed to reload layout, local workers keep their current configuration")
+	}
+
+	// Notify outside the lock, since the receiver is likely to call Get.
+	ids := make([]string, 0, len(changed))
+	for id := range changed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	queueReconfigure(ctx, r.reconfigureQueue, ids)
+}
+
+// readLayout reads & validates the layout file at given path and extracts
+// the configuration of every local worker in it.
+// If the file exists, its modification time and the used templates are returned,
+// even when the content is invalid.
+func readLayout(path string) (map[string]model.LocalWorkerConfig, time.Time, *templateLoader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+	templates := newTemplateLoader(filepath.Join(filepath.Dir(path), templatesFolderName))
+	var layout rawLayout
+	if isYAMLExtension(filepath.Ext(path)) {
+		err = yaml.Unmarshal(content, &layout)
+	} else {
+		err = json.Unmarshal(content, &layout)
+	}
+	if err != nil {
+		return nil, info.ModTime(), templates, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	workers, err := layout.extractWorkers(templates)
+	if err != nil {
+		return nil, info.ModTime(), templates, fmt.Errorf("invalid layout in %s: %w", path, err)
+	}
+	return workers, info.ModTime(), templates, nil
+}
+
+// extractWorkers validates the layout and returns the configuration of all
+// local workers keyed by their ID.
+func (l rawLayout) extractWorkers(templates *templateLoader) (map[string]model.LocalWorkerConfig, error) {
+	type workerRef struct {
+		moduleID string
+		workerID string
+	}
+	var errs validator.Errors
+	result := make(map[string]model.LocalWorkerConfig)
+	workerModule := make(map[string]string)
+	aliases := make(map[string]string)
+	deviceOwners := make(map[model.DeviceID][]workerRef)
+
+	// Collect workers & check global uniqueness
+	for _, m := range l.Modules {
+		objectOwners := make(map[model.ObjectID]string)
+		for _, w := range m.Workers {
+			if w.ID == "" {
+				errs = append(errs, validator.Error{ModuleID: m.ID, Message: "worker has an empty ID"})
+				continue
+			}
+			if other, found := workerModule[w.ID]; found {
+				errs = append(errs, validator.Error{ModuleID: m.ID, WorkerID: w.ID, Message: fmt.Sprintf("worker is also listed in module '%s'", other)})
+				continue
+			}
+			workerModule[w.ID] = m.ID
+			if w.Alias != "" {
+				if other, found := aliases[w.Alias]; found {
+					errs = append(errs, validator.Error{ModuleID: m.ID, WorkerID: w.ID, Message: fmt.Sprintf("alias '%s' is already used by worker '%s'", w.Alias, other)})
+				} else {
+					aliases[w.Alias] = w.ID
+				}
+			}
+			raw, err := templates.expandIncludes(w.rawConfig, 0)
+			if err != nil {
+				errs = append(errs, validator.Error{ModuleID: m.ID, WorkerID: w.ID, Message: err.Error()})
+				continue
+			}
+			conf := raw.toModel()
+			for _, d := range conf.GetDevices() {
+				deviceOwners[d.GetId()] = append(deviceOwners[d.GetId()], workerRef{moduleID: m.ID, workerID: w.ID})
+			}
+			for _, o := range conf.GetObjects() {
+				if other, found := objectOwners[o.GetId()]; found && other != w.ID {
+					errs = append(errs, validator.Error{ModuleID: m.ID, WorkerID: w.ID, ObjectID: o.GetId(), Message: fmt.Sprintf("object ID is also used by worker '%s' of the same module", other)})
+				} else {
+					objectOwners[o.GetId()] = w.ID
+				}
+			}
+			result[w.ID] = conf
+		}
+	}
+
+	// Validate every worker & check references to devices of other workers
+	ids := make([]string, 0, len(result))
+	for id := range result {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		conf := result[id]
+		moduleID := workerModule[id]
+		for _, e := range validator.AsErrors(validator.Validate(conf)) {
+			e.ModuleID = moduleID
+			e.WorkerID = id
+			errs = append(errs, e)
+		}
+		for _, o := range conf.GetObjects() {
+			for _, conn := range o.GetConnections() {
+				for _, p := range conn.GetPins() {
+					if _, found := conf.DeviceByID(p.GetDeviceId()); found {
+						continue
+					}
+					for _, owner := range deviceOwners[p.GetDeviceId()] {
+						errs = append(errs, validator.Error{
+							ModuleID:   moduleID,
+							WorkerID:   id,
+							ObjectID:   o.GetId(),
+							Connection: conn.GetKey(),
+							Message: fmt.Sprintf("device '%s' belongs to worker '%s' of module '%s', objects can only use devices of their own worker",
+								p.GetDeviceId(), owner.workerID, owner.moduleID),
+						})
+					}
+				}
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return result, nil
+}
@@ -18,11 +18,66 @@
 package config
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	model "github.com/binkynet/BinkyNet/apis/v1"
 )
 
+var (
+	// ErrUnknownRevision is returned by a HistoryRegistry when a revision cannot be found.
+	ErrUnknownRevision = errors.New("unknown revision")
+)
+
 // Registry abstracts a local worker configuration registry.
 type Registry interface {
 	// Get returns the configuration for a worker with given ID.
 	Get(id string) (model.LocalWorkerConfig, error)
 }
+
+// HistoryRegistry is a Registry that keeps a history of configurations
+// and can roll back local workers to an earlier configuration.
+type HistoryRegistry interface {
+	Registry
+
+	// History returns the revisions of the configuration of the worker with given ID.
+	// Newest revisions come first.
+	// Returns an error wrapping fs.ErrNotExist if the worker has no configuration.
+	History(id string) ([]Revision, error)
+	// Rollback configures the worker with given ID with its configuration
+	// at the given revision.
+	// If revision is empty, the worker follows the latest configuration again.
+	// Returns an error wrapping ErrUnknownRevision if the revision cannot be found,
+	// or fs.ErrNotExist if the worker has no configuration at that revision.
+	Rollback(ctx context.Context, id, revision string) error
+}
+
+// Revision describes a single revision of a local worker configuration.
+type Revision struct {
+	// Hash of the commit
+	Hash string `json:"hash"`
+	// Time of the commit
+	Time time.Time `json:"time"`
+	// Author of the commit
+	Author string `json:"author"`
+	// First line of the commit message
+	Subject string `json:"subject"`
+	// Active is set if the worker is currently configured with this revision
+	Active bool `json:"active,omitempty"`
+}
+
+// queueReconfigure queues the workers with given IDs for reconfiguration.
+// It gives up when the given context is canceled before all IDs are queued.
+func queueReconfigure(ctx context.Context, reconfigureQueue chan string, ids []string) {
+	if reconfigureQueue == nil {
+		return
+	}
+	for _, id := range ids {
+		select {
+		case reconfigureQueue <- id:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
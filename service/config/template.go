@@ -0,0 +1,266 @@
+// Copyright 2024 Ewout Prangsma
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author Ewout Prangsma
+//
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+	"time"
+)
+
+// Configurations can include templates for repeated module designs.
+// A template is a (YAML or JSON) local worker configuration stored in
+// the `templates` folder next to the configuration files.
+// Templates are expanded with Go's text/template using the parameters
+// given in the include:
+//
+//	include:
+//	  - template: switch-board
+//	    parameters:
+//	      prefix: sw1
+//	      ioAddress: "0x21"
+//	      pinOffset: 4
+//
+// Devices & objects of all includes are merged into the configuration.
+// Devices & objects of the configuration itself override included
+// devices & objects with the same ID.
+
+const (
+	// Name of the folder (relative to the configurations) containing templates
+	templatesFolderName = "templates"
+	// Maximum depth of nested includes
+	maxIncludeDepth = 8
+)
+
+type rawInclude struct {
+	// Name of the template (filename without extension)
+	Template string `json:"template" yaml:"template"`
+	// Parameters used to expand the template
+	Parameters map[string]interface{} `json:"parameters" yaml:"parameters"`
+}
+
+// fileReader reads the content & modification time of a file.
+// A zero modification time means that the file can never change.
+// Returns an error wrapping fs.ErrNotExist if the file does not exist.
+type fileReader func(path string) ([]byte, time.Time, error)
+
+// templateLoader loads & expands templates from a folder.
+type templateLoader struct {
+	folder string
+	read   fileReader
+	// Modification time of all template files used so far (keyed by path)
+	used map[string]time.Time
+}
+
+// newTemplateLoader creates a loader for templates in the given folder.
+func newTemplateLoader(folder string) *templateLoader {
+	return newTemplateLoaderWithReader(folder, readLocalFile)
+}
+
+// newTemplateLoaderWithReader creates a loader for templates in the given folder,
+// that uses the given reader to read template files.
+func newTemplateLoaderWithReader(folder string, read fileReader) *templateLoader {
+	return &templateLoader{
+		folder: folder,
+		read:   read,
+		used:   make(map[string]time.Time),
+	}
+}
+
+// readLocalFile is a fileReader for the local filesystem.
+func readLocalFile(path string) ([]byte, time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return content, info.ModTime(), nil
+}
+
+// expandIncludes returns a copy of the given configuration with all includes
+// expanded and merged.
+func (l *templateLoader) expandIncludes(c rawConfig, depth int) (rawConfig, error) {
+	if len(c.Include) == 0 {
+		return c, nil
+	}
+	if l == nil {
+		return rawConfig{}, fmt.Errorf("includes are not supported here")
+	}
+	if depth >= maxIncludeDepth {
+		return rawConfig{}, fmt.Errorf("includes nested too deep (max %d)", maxIncludeDepth)
+	}
+	var result rawConfig
+	for _, inc := range c.Include {
+		included, err := l.load(inc)
+		if err != nil {
+			return rawConfig{}, err
+		}
+		included, err = l.expandIncludes(included, depth+1)
+		if err != nil {
+			return rawConfig{}, fmt.Errorf("in template '%s': %w", inc.Template, err)
+		}
+		if included.Alias != "" {
+			result.Alias = included.Alias
+		}
+		result.Devices = mergeKeyed(result.Devices, included.Devices)
+		result.Objects = mergeKeyed(result.Objects, included.Objects)
+	}
+	if c.Alias != "" {
+		result.Alias = c.Alias
+	}
+	result.Devices = mergeKeyed(result.Devices, c.Devices)
+	result.Objects = mergeKeyed(result.Objects, c.Objects)
+	return result, nil
+}
+
+// load reads & expands the template referred to by the given include.
+func (l *templateLoader) load(inc rawInclude) (rawConfig, error) {
+	if inc.Template == "" {
+		return rawConfig{}, fmt.Errorf("include without template name")
+	}
+	var filePath string
+	var content []byte
+	for _, ext := range configExtensions {
+		p := filepath.Join(l.folder, inc.Template+ext)
+		x, modTime, err := l.read(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return rawConfig{}, err
+		}
+		filePath, content = p, x
+		l.used[filePath] = modTime
+		break
+	}
+	if filePath == "" {
+		return rawConfig{}, fmt.Errorf("template '%s' not found in %s", inc.Template, l.folder)
+	}
+	tmpl, err := template.New(inc.Template).
+		Option("missingkey=error").
+		Funcs(templateFuncs).
+		Parse(string(content))
+	if err != nil {
+		return rawConfig{}, fmt.Errorf("failed to parse template %s: %w", filePath, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, inc.Parameters); err != nil {
+		return rawConfig{}, fmt.Errorf("failed to expand template %s: %w", filePath, err)
+	}
+	result, err := unmarshalRawConfig(buf.Bytes(), isYAMLExtension(filepath.Ext(filePath)))
+	if err != nil {
+		return rawConfig{}, fmt.Errorf("failed to parse expanded template %s: %w", filePath, err)
+	}
+	return result, nil
+}
+
+// changed returns true if any of the used template files has changed
+// since it was loaded.
+func (l *templateLoader) changed() bool {
+	if l == nil {
+		return false
+	}
+	for p, modTime := range l.used {
+		if modTime.IsZero() {
+			// Cannot change
+			continue
+		}
+		if info, err := os.Stat(p); err != nil || info.ModTime() != modTime {
+			return true
+		}
+	}
+	return false
+}
+
+var templateFuncs = template.FuncMap{
+	// add returns the sum of all (integer) arguments.
+	"add": func(values ...interface{}) (int, error) {
+		sum := 0
+		for _, v := range values {
+			x, err := toInt(v)
+			if err != nil {
+				return 0, err
+			}
+			sum += x
+		}
+		return sum, nil
+	},
+	// default returns the given value, or the default when the value is not set.
+	"default": func(def, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return def
+		}
+		return value
+	},
+}
+
+// toInt converts a template parameter into an integer.
+func toInt(v interface{}) (int, error) {
+	switch x := v.(type) {
+	case int:
+		return x, nil
+	case int64:
+		return int(x), nil
+	case uint64:
+		return int(x), nil
+	case float64:
+		if x != math.Trunc(x) {
+			return 0, fmt.Errorf("cannot convert %v to an integer without losing its fraction", x)
+		}
+		return int(x), nil
+	case string:
+		i, err := strconv.ParseInt(x, 0, 64)
+		return int(i), err
+	default:
+		return 0, fmt.Errorf("cannot convert %v (%T) to an integer", v, v)
+	}
+}
+
+// mergeKeyed returns the entries of base, with entries replaced by those
+// of overrides that have the same key. Other entries of overrides are appended.
+func mergeKeyed[T any](base, overrides keyedList[T]) keyedList[T] {
+	result := make(keyedList[T], 0, len(base)+len(overrides))
+	result = append(result, base...)
+	index := make(map[string]int)
+	for i := range result {
+		if k, ok := any(&result[i]).(keyed); ok {
+			index[*k.key()] = i
+		}
+	}
+	for _, entry := range overrides {
+		if k, ok := any(&entry).(keyed); ok {
+			if i, found := index[*k.key()]; found {
+				result[i] = entry
+				continue
+			}
+			index[*k.key()] = len(result)
+		}
+		result = append(result, entry)
+	}
+	return result
+}
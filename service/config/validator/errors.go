@@ -0,0 +1,88 @@
+// Copyright 2024 Ewout Prangsma
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author Ewout Prangsma
+//
+
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	model "github.com/binkynet/BinkyNet/apis/v1"
+)
+
+// Error describes a single problem found in a local worker configuration.
+type Error struct {
+	// ID of the module the problem relates to (if any)
+	ModuleID string `json:"module_id,omitempty"`
+	// ID of the local worker the problem relates to (if any)
+	WorkerID string `json:"worker_id,omitempty"`
+	// ID of the device the problem relates to (if any)
+	DeviceID model.DeviceID `json:"device_id,omitempty"`
+	// ID of the object the problem relates to (if any)
+	ObjectID model.ObjectID `json:"object_id,omitempty"`
+	// Name of the connection (of the object) the problem relates to (if any)
+	Connection model.ConnectionName `json:"connection,omitempty"`
+	// Description of the problem
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e Error) Error() string {
+	var parts []string
+	if e.ModuleID != "" {
+		parts = append(parts, fmt.Sprintf("module '%s'", e.ModuleID))
+	}
+	if e.WorkerID != "" {
+		parts = append(parts, fmt.Sprintf("worker '%s'", e.WorkerID))
+	}
+	if e.DeviceID != "" {
+		parts = append(parts, fmt.Sprintf("device '%s'", e.DeviceID))
+	}
+	if e.ObjectID != "" {
+		parts = append(parts, fmt.Sprintf("object '%s'", e.ObjectID))
+	}
+	if e.Connection != "" {
+		parts = append(parts, fmt.Sprintf("connection '%s'", e.Connection))
+	}
+	if len(parts) == 0 {
+		return e.Message
+	}
+	return strings.Join(parts, ", ") + ": " + e.Message
+}
+
+// Errors is a list of problems found in a local worker configuration.
+type Errors []Error
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, x := range e {
+		lines = append(lines, x.Error())
+	}
+	return fmt.Sprintf("%d configuration error(s):\n- %s", len(e), strings.Join(lines, "\n- "))
+}
+
+// AsErrors returns the list of problems contained in the given error.
+// Returns nil if the error is not (or does not wrap) an Errors list.
+func AsErrors(err error) Errors {
+	var list Errors
+	if errors.As(err, &list) {
+		return list
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2024 Ewout Prangsma
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author Ewout Prangsma
+//
+
+package validator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	model "github.com/binkynet/BinkyNet/apis/v1"
+)
+
+// Validate checks the given local worker configuration for problems.
+// Returns nil if the configuration is valid, otherwise an Errors list
+// describing all problems found.
+func Validate(conf model.LocalWorkerConfig) error {
+	v := &validator{
+		conf:    conf,
+		devices: make(map[model.DeviceID]*model.Device),
+	}
+	v.validateDevices()
+	v.validateObjects()
+	if len(v.errors) > 0 {
+		return v.errors
+	}
+	return nil
+}
+
+type validator struct {
+	conf    model.LocalWorkerConfig
+	devices map[model.DeviceID]*model.Device
+	errors  Errors
+}
+
+// addDeviceError records a problem with a device.
+func (v *validator) addDeviceError(id model.DeviceID, format string, args ...interface{}) {
+	v.errors = append(v.errors, Error{
+		DeviceID: id,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// addObjectError records a problem with an object.
+func (v *validator) addObjectError(id model.ObjectID, format string, args ...interface{}) {
+	v.errors = append(v.errors, Error{
+		ObjectID: id,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// addConnectionError records a problem with a connection of an object.
+func (v *validator) addConnectionError(id model.ObjectID, conn model.ConnectionName, format string, args ...interface{}) {
+	v.errors = append(v.errors, Error{
+		ObjectID:   id,
+		Connection: conn,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// validateDevices checks all devices and builds the device lookup table.
+func (v *validator) validateDevices() {
+	addresses := make(map[string]model.DeviceID)
+	for _, d := range v.conf.GetDevices() {
+		if d == nil {
+			continue
+		}
+		if d.GetId() == "" {
+			v.addDeviceError(d.GetId(), "device has an empty ID")
+			continue
+		}
+		if _, found := v.devices[d.GetId()]; found {
+			v.addDeviceError(d.GetId(), "duplicate device ID")
+			continue
+		}
+		v.devices[d.GetId()] = d
+		if err := d.GetType().Validate(); err != nil {
+			v.addDeviceError(d.GetId(), "%s", err)
+		}
+		if d.GetAddress() == "" {
+			v.addDeviceError(d.GetId(), "address is empty")
+			continue
+		}
+		if !isI2CDevice(d.GetType()) {
+			continue
+		}
+		addr, err := strconv.ParseUint(d.GetAddress(), 0, 8)
+		if err != nil {
+			v.addDeviceError(d.GetId(), "address '%s' is not a valid I2C address", d.GetAddress())
+			continue
+		}
+		key := fmt.Sprintf("0x%02x", addr)
+		if other, found := addresses[key]; found {
+			v.addDeviceError(d.GetId(), "I2C address %s is already used by device '%s'", key, other)
+		} else {
+			addresses[key] = d.GetId()
+		}
+	}
+}
+
+// validateObjects checks all objects and their connections.
+func (v *validator) validateObjects() {
+	ids := make(map[model.ObjectID]struct{})
+	for _, o := range v.conf.GetObjects() {
+		if o == nil {
+			continue
+		}
+		id := o.GetId()
+		if id == "" {
+			v.addObjectError(id, "object has an empty ID")
+			continue
+		}
+		if _, found := ids[id]; found {
+			v.addObjectError(id, "duplicate object ID")
+			continue
+		}
+		ids[id] = struct{}{}
+		if !isKnownObjectType(o.GetType()) {
+			v.addObjectError(id, "unknown object type '%s'", o.GetType())
+			continue
+		}
+
+		// Check object configuration
+		expectedConfig := o.GetType().ExpectedConfigurations()
+		for key, value := range o.GetConfiguration() {
+			if !slices.Contains(expectedConfig, key) {
+				v.addObjectError(id, "unexpected configuration key '%s'", key)
+			} else if err := key.ValidateValue(value); err != nil {
+				v.addObjectError(id, "invalid value '%s' for configuration key '%s': %s", value, key, err)
+			}
+		}
+
+		// Check connections
+		required, optional := o.GetType().ExpectedConnections()
+		seen := make(map[model.ConnectionName]struct{})
+		for _, conn := range o.GetConnections() {
+			if conn == nil {
+				continue
+			}
+			name := conn.GetKey()
+			if _, found := seen[name]; found {
+				v.addConnectionError(id, name, "duplicate connection")
+				continue
+			}
+			seen[name] = struct{}{}
+			if !slices.Contains(required, name) && !slices.Contains(optional, name) {
+				v.addConnectionError(id, name, "unexpected connection for object type '%s'", o.GetType())
+				continue
+			}
+			v.validateConnection(id, conn)
+		}
+		for _, name := range required {
+			if _, found := seen[name]; !found {
+				v.addConnectionError(id, name, "missing required connection")
+			}
+		}
+	}
+}
+
+// validateConnection checks the pins and configuration of a single connection.
+func (v *validator) validateConnection(id model.ObjectID, conn *model.Connection) {
+	name := conn.GetKey()
+	if expected := name.ExpectedPins(); len(conn.GetPins()) != expected {
+		v.addConnectionError(id, name, "expected %d pin(s), got %d", expected, len(conn.GetPins()))
+	}
+	for idx, pin := range conn.GetPins() {
+		if pin == nil {
+			continue
+		}
+		dev, found := v.devices[pin.GetDeviceId()]
+		if !found {
+			v.addConnectionError(id, name, "pin %d refers to unknown device '%s'", idx, pin.GetDeviceId())
+			continue
+		}
+		if pin.GetDeviceIndex() == 0 {
+			v.addConnectionError(id, name, "pin %d has an invalid index 0 (indexes start at 1)", idx)
+		} else if maxIndex := maxPinIndex(dev.GetType()); maxIndex > 0 && int(pin.GetDeviceIndex()) > maxIndex {
+			v.addConnectionError(id, name, "pin %d has index %d, which is out of range for device '%s' of type '%s' (1..%d)",
+				idx, pin.GetDeviceIndex(), dev.GetId(), dev.GetType(), maxIndex)
+		}
+	}
+	required, optional := name.ExpectedConfigurations()
+	for key, value := range conn.GetConfiguration() {
+		if !slices.Contains(required, key) && !slices.Contains(optional, key) {
+			v.addConnectionError(id, name, "unexpected configuration key '%s'", key)
+		} else if err := key.ValidateValue(value); err != nil {
+			v.addConnectionError(id, name, "invalid value '%s' for configuration key '%s': %s", value, key, err)
+		}
+	}
+	for _, key := range required {
+		if _, found := conn.GetConfiguration()[key]; !found {
+			v.addConnectionError(id, name, "missing required configuration key '%s'", key)
+		}
+	}
+}
+
+// isI2CDevice returns true if devices of the given type are connected to the I2C bus.
+func isI2CDevice(t model.DeviceType) bool {
+	switch t {
+	case model.DeviceTypeMQTTGPIO, model.DeviceTypeMQTTServo:
+		return false
+	default:
+		return true
+	}
+}
+
+// maxPinIndex returns the highest pin index of devices of the given type.
+// Returns 0 if unknown.
+func maxPinIndex(t model.DeviceType) int {
+	switch t {
+	case model.DeviceTypeMCP23008, model.DeviceTypePCF8574:
+		return 8
+	case model.DeviceTypeMCP23017, model.DeviceTypePCA9685:
+		return 16
+	case model.DeviceTypeADS1115:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// isKnownObjectType returns true if the given type is a known object type.
+func isKnownObjectType(t model.ObjectType) bool {
+	return slices.Contains(model.AllObjectTypes(), t)
+}
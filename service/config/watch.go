@@ -0,0 +1,118 @@
+// Copyright 2024 Ewout Prangsma
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author Ewout Prangsma
+//
+
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// Time to wait for more filesystem events before reconfiguring
+	watchDebounceDelay = time.Millisecond * 250
+	// Interval between checks for changes when the folder cannot be watched
+	pollInterval = time.Second * 5
+)
+
+// folderWatcher watches a configuration folder and its templates folder
+// for changes.
+type folderWatcher struct {
+	log    zerolog.Logger
+	folder string
+	// Called for every filesystem event (except chmod) in the folder or its templates
+	// folder. Returns true if the event affects the configuration.
+	handleEvent func(evt fsnotify.Event) bool
+	// Called once no more affecting events have arrived for watchDebounceDelay.
+	applyChanges func(ctx context.Context)
+	// Called periodically when the folder cannot be watched, and when events may
+	// have been lost. Detects all changes itself.
+	checkChanges func(ctx context.Context)
+}
+
+// run keeps watching the folder until the given context is canceled.
+// If the folder cannot be watched, it falls back to polling.
+func (w folderWatcher) run(ctx context.Context) {
+	watcher, err := w.newWatcher()
+	if err != nil {
+		w.log.Warn().Err(err).Str("folder", w.folder).Msg("Failed to watch configuration folder, falling back to polling")
+		w.runPolling(ctx)
+		return
+	}
+	defer watcher.Close()
+
+	templatesFolder := filepath.Join(filepath.Clean(w.folder), templatesFolderName)
+	var debounce <-chan time.Time
+	for {
+		select {
+		case evt := <-watcher.Events:
+			if evt.Op == fsnotify.Chmod {
+				continue
+			}
+			if filepath.Clean(evt.Name) == templatesFolder && evt.Has(fsnotify.Create) {
+				watcher.Add(templatesFolder)
+			}
+			if w.handleEvent(evt) {
+				// Wait for more events (editors often write a file in multiple steps)
+				debounce = time.After(watchDebounceDelay)
+			}
+		case err := <-watcher.Errors:
+			// Events may have been lost
+			w.log.Error().Err(err).Str("folder", w.folder).Msg("Error while watching configuration folder")
+			w.checkChanges(ctx)
+		case <-debounce:
+			debounce = nil
+			w.applyChanges(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runPolling keeps checking for changes until the given context is canceled.
+func (w folderWatcher) runPolling(ctx context.Context) {
+	for {
+		// Check once
+		w.checkChanges(ctx)
+
+		select {
+		case <-time.After(pollInterval):
+			// Continue
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// newWatcher creates a filesystem watcher for the folder and its templates folder.
+func (w folderWatcher) newWatcher() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(w.folder); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	// The templates folder is optional
+	watcher.Add(filepath.Join(w.folder, templatesFolderName))
+	return watcher, nil
+}
@@ -0,0 +1,23 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package control contains the LayoutControlService.
+// This service is used by throttles, panels & other controller apps
+// to request changes in the layout & watch its actual state,
+// and by operator tools to manage local workers.
+// It is defined in layout_control.proto, which uses the messages of
+// the BinkyNet API, so clients can be generated for any language.
+package control
+
+//go:generate sh -c "protoc -I .:$(go list -m -f {{.Dir}} github.com/binkynet/BinkyNet)/apis/v1:$(go list -m -f {{.Dir}} github.com/binkynet/BinkyNet)/proto_vendor:$(go list -m -f {{.Dir}} github.com/binkynet/BinkyNet)/proto_vendor/github.com/gogo/protobuf/protobuf --gofast_out=Mtypes.proto=github.com/binkynet/BinkyNet/apis/v1,Mnetwork.proto=github.com/binkynet/BinkyNet/apis/v1,plugins=grpc,paths=source_relative:. ./layout_control.proto"
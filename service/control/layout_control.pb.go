@@ -0,0 +1,2942 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: layout_control.proto
+
+package control
+
+import (
+	context "context"
+	fmt "fmt"
+	v1 "github.com/binkynet/BinkyNet/apis/v1"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Liveness of a local worker
+type WorkerLiveness struct {
+	// ID of the local worker
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Liveness of the local worker: online, stale or offline
+	Liveness string `protobuf:"bytes,2,opt,name=liveness,proto3" json:"liveness,omitempty"`
+	// Time the local worker last reported its actual state (in unix seconds)
+	LastUpdatedUnixtime  int64    `protobuf:"varint,3,opt,name=last_updated_unixtime,json=lastUpdatedUnixtime,proto3" json:"last_updated_unixtime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WorkerLiveness) Reset()         { *m = WorkerLiveness{} }
+func (m *WorkerLiveness) String() string { return proto.CompactTextString(m) }
+func (*WorkerLiveness) ProtoMessage()    {}
+func (*WorkerLiveness) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7e54e3ac9cfbc165, []int{0}
+}
+func (m *WorkerLiveness) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *WorkerLiveness) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_WorkerLiveness.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *WorkerLiveness) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WorkerLiveness.Merge(m, src)
+}
+func (m *WorkerLiveness) XXX_Size() int {
+	return m.Size()
+}
+func (m *WorkerLiveness) XXX_DiscardUnknown() {
+	xxx_messageInfo_WorkerLiveness.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WorkerLiveness proto.InternalMessageInfo
+
+func (m *WorkerLiveness) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *WorkerLiveness) GetLiveness() string {
+	if m != nil {
+		return m.Liveness
+	}
+	return ""
+}
+
+func (m *WorkerLiveness) GetLastUpdatedUnixtime() int64 {
+	if m != nil {
+		return m.LastUpdatedUnixtime
+	}
+	return 0
+}
+
+// Revisions of the configuration of a local worker
+type WorkerConfigHistory struct {
+	// ID of the local worker
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Revisions of the configuration, newest first
+	Revisions            []*WorkerConfigRevision `protobuf:"bytes,2,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *WorkerConfigHistory) Reset()         { *m = WorkerConfigHistory{} }
+func (m *WorkerConfigHistory) String() string { return proto.CompactTextString(m) }
+func (*WorkerConfigHistory) ProtoMessage()    {}
+func (*WorkerConfigHistory) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7e54e3ac9cfbc165, []int{1}
+}
+func (m *WorkerConfigHistory) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *WorkerConfigHistory) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_WorkerConfigHistory.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *WorkerConfigHistory) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WorkerConfigHistory.Merge(m, src)
+}
+func (m *WorkerConfigHistory) XXX_Size() int {
+	return m.Size()
+}
+func (m *WorkerConfigHistory) XXX_DiscardUnknown() {
+	xxx_messageInfo_WorkerConfigHistory.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WorkerConfigHistory proto.InternalMessageInfo
+
+func (m *WorkerConfigHistory) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *WorkerConfigHistory) GetRevisions() []*WorkerConfigRevision {
+	if m != nil {
+		return m.Revisions
+	}
+	return nil
+}
+
+// A single revision of the configuration of a local worker
+type WorkerConfigRevision struct {
+	// Hash of the commit
+	Hash string `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	// Time of the commit (in unix seconds)
+	Unixtime int64 `protobuf:"varint,2,opt,name=unixtime,proto3" json:"unixtime,omitempty"`
+	// Author of the commit
+	Author string `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	// First line of the commit message
+	Subject string `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	// Set if the local worker is currently configured with this revision
+	Active               bool     `protobuf:"varint,5,opt,name=active,proto3" json:"active,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WorkerConfigRevision) Reset()         { *m = WorkerConfigRevision{} }
+func (m *WorkerConfigRevision) String() string { return proto.CompactTextString(m) }
+func (*WorkerConfigRevision) ProtoMessage()    {}
+func (*WorkerConfigRevision) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7e54e3ac9cfbc165, []int{2}
+}
+func (m *WorkerConfigRevision) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *WorkerConfigRevision) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_WorkerConfigRevision.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *WorkerConfigRevision) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WorkerConfigRevision.Merge(m, src)
+}
+func (m *WorkerConfigRevision) XXX_Size() int {
+	return m.Size()
+}
+func (m *WorkerConfigRevision) XXX_DiscardUnknown() {
+	xxx_messageInfo_WorkerConfigRevision.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WorkerConfigRevision proto.InternalMessageInfo
+
+func (m *WorkerConfigRevision) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *WorkerConfigRevision) GetUnixtime() int64 {
+	if m != nil {
+		return m.Unixtime
+	}
+	return 0
+}
+
+func (m *WorkerConfigRevision) GetAuthor() string {
+	if m != nil {
+		return m.Author
+	}
+	return ""
+}
+
+func (m *WorkerConfigRevision) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *WorkerConfigRevision) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+// Request to configure a local worker with its configuration at an earlier revision
+type RollbackWorkerConfigRequest struct {
+	// ID of the local worker
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Revision (commit or ref) to roll back to.
+	// If empty, the local worker follows the latest configuration again.
+	Revision             string   `protobuf:"bytes,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RollbackWorkerConfigRequest) Reset()         { *m = RollbackWorkerConfigRequest{} }
+func (m *RollbackWorkerConfigRequest) String() string { return proto.CompactTextString(m) }
+func (*RollbackWorkerConfigRequest) ProtoMessage()    {}
+func (*RollbackWorkerConfigRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7e54e3ac9cfbc165, []int{3}
+}
+func (m *RollbackWorkerConfigRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RollbackWorkerConfigRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RollbackWorkerConfigRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RollbackWorkerConfigRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RollbackWorkerConfigRequest.Merge(m, src)
+}
+func (m *RollbackWorkerConfigRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *RollbackWorkerConfigRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RollbackWorkerConfigRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RollbackWorkerConfigRequest proto.InternalMessageInfo
+
+func (m *RollbackWorkerConfigRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *RollbackWorkerConfigRequest) GetRevision() string {
+	if m != nil {
+		return m.Revision
+	}
+	return ""
+}
+
+// An object of which the actual state differs from its requested state
+type DivergentObject struct {
+	// Domain of the object: loc, output or switch
+	Domain string `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	// Address of the object
+	Address string `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	// Time the divergence was first detected (in unix seconds)
+	SinceUnixtime int64 `protobuf:"varint,3,opt,name=since_unixtime,json=sinceUnixtime,proto3" json:"since_unixtime,omitempty"`
+	// Number of times the request has been resent
+	Attempts int32 `protobuf:"varint,4,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// Time of the next resend (in unix seconds)
+	NextAttemptUnixtime  int64    `protobuf:"varint,5,opt,name=next_attempt_unixtime,json=nextAttemptUnixtime,proto3" json:"next_attempt_unixtime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DivergentObject) Reset()         { *m = DivergentObject{} }
+func (m *DivergentObject) String() string { return proto.CompactTextString(m) }
+func (*DivergentObject) ProtoMessage()    {}
+func (*DivergentObject) Descriptor() ([]byte, []int) {
+	return fileDescriptor_7e54e3ac9cfbc165, []int{4}
+}
+func (m *DivergentObject) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DivergentObject) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DivergentObject.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DivergentObject) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DivergentObject.Merge(m, src)
+}
+func (m *DivergentObject) XXX_Size() int {
+	return m.Size()
+}
+func (m *DivergentObject) XXX_DiscardUnknown() {
+	xxx_messageInfo_DivergentObject.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DivergentObject proto.InternalMessageInfo
+
+func (m *DivergentObject) GetDomain() string {
+	if m != nil {
+		return m.Domain
+	}
+	return ""
+}
+
+func (m *DivergentObject) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *DivergentObject) GetSinceUnixtime() int64 {
+	if m != nil {
+		return m.SinceUnixtime
+	}
+	return 0
+}
+
+func (m *DivergentObject) GetAttempts() int32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *DivergentObject) GetNextAttemptUnixtime() int64 {
+	if m != nil {
+		return m.NextAttemptUnixtime
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*WorkerLiveness)(nil), "binkynet.netmanager.v1.WorkerLiveness")
+	proto.RegisterType((*WorkerConfigHistory)(nil), "binkynet.netmanager.v1.WorkerConfigHistory")
+	proto.RegisterType((*WorkerConfigRevision)(nil), "binkynet.netmanager.v1.WorkerConfigRevision")
+	proto.RegisterType((*RollbackWorkerConfigRequest)(nil), "binkynet.netmanager.v1.RollbackWorkerConfigRequest")
+	proto.RegisterType((*DivergentObject)(nil), "binkynet.netmanager.v1.DivergentObject")
+}
+
+func init() { proto.RegisterFile("layout_control.proto", fileDescriptor_7e54e3ac9cfbc165) }
+
+var fileDescriptor_7e54e3ac9cfbc165 = []byte{
+	// 863 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0x51, 0x6f, 0x1b, 0x45,
+	0x10, 0xe6, 0x9c, 0x26, 0xc4, 0x13, 0x9c, 0x96, 0x8d, 0x1b, 0x8c, 0x23, 0x45, 0x91, 0x25, 0x20,
+	0x12, 0xd5, 0xc5, 0x49, 0xd5, 0x17, 0x50, 0x29, 0x69, 0x8a, 0x02, 0xc8, 0x34, 0xe8, 0xac, 0x50,
+	0xa9, 0x0f, 0x58, 0xe7, 0xf3, 0x10, 0x2f, 0x3e, 0xdf, 0x5e, 0x77, 0xe7, 0x2e, 0xf5, 0xbf, 0xe0,
+	0x91, 0xdf, 0xc0, 0x4f, 0xe0, 0x17, 0xf0, 0xc8, 0x13, 0xcf, 0x28, 0xfc, 0x11, 0x74, 0xbb, 0x77,
+	0x67, 0xec, 0xac, 0x8d, 0xf3, 0xe6, 0x6f, 0x66, 0xbe, 0x6f, 0xbf, 0xd9, 0x1d, 0x79, 0x0e, 0xea,
+	0xa1, 0x3f, 0x11, 0x09, 0xf5, 0x02, 0x11, 0x91, 0x14, 0xa1, 0x1b, 0x4b, 0x41, 0x82, 0xed, 0xf6,
+	0x79, 0x34, 0x9a, 0x44, 0x48, 0x6e, 0x84, 0x34, 0xf6, 0x23, 0xff, 0x0a, 0xa5, 0x9b, 0x1e, 0x37,
+	0xb7, 0x68, 0x12, 0xa3, 0x32, 0x45, 0xcd, 0x5a, 0x84, 0x74, 0x2d, 0xe4, 0xc8, 0xc0, 0x56, 0x0c,
+	0xdb, 0xaf, 0x84, 0x1c, 0xa1, 0xec, 0xf0, 0x14, 0x23, 0x54, 0x8a, 0x6d, 0x43, 0x85, 0x0f, 0x1a,
+	0xce, 0x81, 0x73, 0x58, 0xf5, 0x2a, 0x7c, 0xc0, 0x9a, 0xb0, 0x19, 0xe6, 0xb9, 0x46, 0x45, 0x47,
+	0x4b, 0xcc, 0x4e, 0xe0, 0x61, 0xe8, 0x2b, 0xea, 0x25, 0xf1, 0xc0, 0x27, 0x1c, 0xf4, 0x92, 0x88,
+	0xbf, 0x25, 0x3e, 0xc6, 0xc6, 0xda, 0x81, 0x73, 0xb8, 0xe6, 0xed, 0x64, 0xc9, 0x4b, 0x93, 0xbb,
+	0xcc, 0x53, 0xad, 0x37, 0xb0, 0x63, 0x4e, 0x3c, 0x13, 0xd1, 0x4f, 0xfc, 0xea, 0x6b, 0xae, 0x48,
+	0xc8, 0xc9, 0xad, 0x63, 0xbf, 0x85, 0xaa, 0xc4, 0x94, 0x2b, 0x2e, 0xa2, 0xec, 0xdc, 0xb5, 0xc3,
+	0xad, 0x93, 0x47, 0xae, 0xbd, 0x41, 0xf7, 0xbf, 0x7a, 0x5e, 0x4e, 0xf2, 0xa6, 0xf4, 0xd6, 0x2f,
+	0x0e, 0xd4, 0x6d, 0x35, 0x8c, 0xc1, 0xbd, 0xa1, 0xaf, 0x86, 0xf9, 0xb1, 0xfa, 0x77, 0xd6, 0x6f,
+	0xd9, 0x46, 0x45, 0xb7, 0x51, 0x62, 0xb6, 0x0b, 0x1b, 0x7e, 0x42, 0x43, 0x21, 0x75, 0x83, 0x55,
+	0x2f, 0x47, 0xac, 0x01, 0xef, 0xaa, 0xa4, 0xff, 0x33, 0x06, 0xd4, 0xb8, 0xa7, 0x13, 0x05, 0xd4,
+	0x8c, 0x80, 0x78, 0x8a, 0x8d, 0xf5, 0x03, 0xe7, 0x70, 0xd3, 0xcb, 0x51, 0xeb, 0x1b, 0xd8, 0xf3,
+	0x44, 0x18, 0xf6, 0xfd, 0x60, 0x34, 0xeb, 0xec, 0x4d, 0x82, 0x8a, 0x6c, 0x8f, 0x50, 0xb4, 0x53,
+	0x3c, 0x42, 0x81, 0x5b, 0xbf, 0x3b, 0x70, 0xff, 0x05, 0x4f, 0x51, 0x5e, 0x61, 0x44, 0x17, 0xe5,
+	0xb1, 0x03, 0x31, 0xf6, 0x79, 0x94, 0x6b, 0xe4, 0x28, 0x33, 0xea, 0x0f, 0x06, 0x72, 0xfa, 0x96,
+	0x05, 0x64, 0x1f, 0xc1, 0xb6, 0xe2, 0x51, 0x80, 0xf3, 0x6f, 0x58, 0xd3, 0xd1, 0xe2, 0xf5, 0x32,
+	0x23, 0x3e, 0x11, 0x8e, 0x63, 0x52, 0xba, 0xd5, 0x75, 0xaf, 0xc4, 0xd9, 0x34, 0x44, 0xf8, 0x96,
+	0x7a, 0x79, 0x60, 0xaa, 0xb4, 0x6e, 0xa6, 0x21, 0x4b, 0x9e, 0x9a, 0x5c, 0xa1, 0x77, 0xf2, 0x57,
+	0x0d, 0xea, 0x1d, 0x3d, 0xcc, 0x67, 0x66, 0x96, 0xbb, 0x28, 0x53, 0x1e, 0x20, 0xfb, 0x02, 0xee,
+	0x77, 0x91, 0xbe, 0x17, 0xd7, 0x28, 0x8b, 0x4b, 0xf9, 0x60, 0xfa, 0xfe, 0xe9, 0xb1, 0xab, 0x53,
+	0x5d, 0xf2, 0x09, 0x9b, 0x6c, 0x26, 0xf1, 0xd5, 0x38, 0xa6, 0x09, 0x3b, 0x81, 0xcd, 0xf3, 0x9c,
+	0xcf, 0x2c, 0xf9, 0x39, 0x8e, 0xa9, 0x7b, 0x0a, 0xf0, 0xca, 0xa7, 0x60, 0x68, 0xd0, 0x87, 0x33,
+	0x15, 0x3a, 0x71, 0x11, 0x53, 0x36, 0x50, 0x36, 0x72, 0xdb, 0x61, 0x4f, 0xa0, 0xd6, 0x45, 0xea,
+	0x88, 0xa0, 0x30, 0xfc, 0x60, 0xa6, 0xac, 0x23, 0x02, 0xab, 0xd3, 0x47, 0xb0, 0x71, 0xae, 0x69,
+	0x96, 0xfa, 0x5b, 0x11, 0xf6, 0x19, 0x54, 0xb5, 0x95, 0x8e, 0x08, 0xd4, 0x32, 0x8b, 0xb7, 0x98,
+	0x6d, 0x87, 0x7d, 0x0e, 0x0f, 0xba, 0x48, 0xdd, 0x6b, 0x4e, 0xc1, 0xb0, 0xf0, 0xb8, 0x33, 0x53,
+	0x67, 0x72, 0x56, 0x9b, 0x4f, 0xa0, 0x7a, 0x5e, 0x90, 0xed, 0x2c, 0x5b, 0x90, 0x9d, 0x42, 0x4d,
+	0xfb, 0x32, 0x10, 0x97, 0x7a, 0xb6, 0x09, 0x94, 0xb6, 0x2f, 0x12, 0x8a, 0x13, 0xb2, 0xdb, 0x36,
+	0xb9, 0x25, 0xb6, 0x4d, 0x81, 0x9d, 0x65, 0x0b, 0xb2, 0x2f, 0xe1, 0x3d, 0x63, 0x4d, 0xc3, 0x3b,
+	0xb8, 0x36, 0x84, 0xb6, 0x93, 0x1f, 0xdc, 0xc5, 0x48, 0x09, 0x39, 0x7f, 0x5f, 0x3a, 0xd8, 0xb4,
+	0x05, 0xcb, 0x83, 0x0d, 0xbc, 0xcb, 0x75, 0x69, 0x42, 0xdb, 0xc9, 0x27, 0xff, 0x2c, 0x14, 0xc1,
+	0x68, 0x85, 0xc9, 0x37, 0x75, 0xc5, 0xe4, 0x1b, 0xb4, 0xf2, 0xe4, 0xeb, 0xf2, 0xb6, 0xc3, 0x9e,
+	0xc2, 0x56, 0x87, 0x2b, 0x32, 0xff, 0x64, 0xca, 0x7a, 0x6a, 0x63, 0x7e, 0x1e, 0xfd, 0xd0, 0x94,
+	0xb7, 0x1d, 0xf6, 0x4c, 0x5f, 0x95, 0x81, 0x6c, 0x61, 0xe1, 0x62, 0x09, 0x76, 0x09, 0xef, 0x97,
+	0x02, 0xe5, 0x22, 0x5b, 0x2c, 0xf4, 0xf1, 0xf2, 0x45, 0x52, 0x2a, 0xbc, 0x86, 0x1d, 0xdd, 0xfc,
+	0x5c, 0x78, 0xc9, 0xf5, 0xac, 0xa8, 0xac, 0x87, 0x7a, 0xcb, 0x43, 0xb5, 0x42, 0xd7, 0xb6, 0xa1,
+	0x3e, 0x87, 0xed, 0x17, 0x5c, 0x05, 0x22, 0x45, 0xf9, 0xbf, 0xfc, 0xbd, 0x99, 0x4c, 0x41, 0xf3,
+	0x50, 0x25, 0x21, 0xb1, 0x1e, 0xec, 0x96, 0x17, 0x37, 0xbb, 0x8f, 0x17, 0x0b, 0x7e, 0xba, 0xca,
+	0x1a, 0x2e, 0x64, 0x7a, 0x50, 0xb7, 0xed, 0x39, 0xf6, 0x78, 0x91, 0xc8, 0x92, 0xad, 0x68, 0xbd,
+	0x8a, 0x67, 0xf0, 0xd0, 0xc3, 0x40, 0x97, 0x25, 0x12, 0x4f, 0xc3, 0x70, 0xd9, 0x10, 0xda, 0x04,
+	0x2e, 0xa1, 0x9e, 0xcd, 0xee, 0xdc, 0x06, 0xb5, 0xf3, 0x3f, 0x59, 0xe4, 0x7a, 0x8e, 0xdd, 0x76,
+	0x9e, 0xff, 0xf8, 0xc7, 0xcd, 0xbe, 0xf3, 0xe7, 0xcd, 0xbe, 0xf3, 0xf7, 0xcd, 0xbe, 0xf3, 0xeb,
+	0x3f, 0xfb, 0xef, 0xbc, 0x76, 0xaf, 0x38, 0x0d, 0x93, 0xbe, 0x1b, 0x88, 0xf1, 0x51, 0x21, 0x73,
+	0xf4, 0x12, 0xe9, 0x3b, 0x23, 0x73, 0xa4, 0xcc, 0xe6, 0x3b, 0xca, 0x3f, 0xea, 0x7e, 0xab, 0xec,
+	0x3d, 0xcf, 0xaa, 0x5e, 0x22, 0xb9, 0xd3, 0x2a, 0xf7, 0x34, 0xe6, 0xca, 0xfd, 0xe1, 0xb8, 0xbf,
+	0xa1, 0xbf, 0xdf, 0x1e, 0xff, 0x3b, 0x00, 0xd7, 0x68, 0x6e, 0xd4, 0x0b, 0x0a, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// LayoutControlServiceClient is the client API for LayoutControlService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type LayoutControlServiceClient interface {
+	// Set the requested power state
+	SetPowerRequest(ctx context.Context, in *v1.PowerState, opts ...grpc.CallOption) (*v1.Empty, error)
+	// Get the requested & actual power state
+	GetPower(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (*v1.Power, error)
+	// Watch power changes
+	WatchPower(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchPowerClient, error)
+	// Set the requested state of a loc
+	SetLocRequest(ctx context.Context, in *v1.Loc, opts ...grpc.CallOption) (*v1.Empty, error)
+	// Get the state of the loc with the address of the given loc
+	GetLoc(ctx context.Context, in *v1.Loc, opts ...grpc.CallOption) (*v1.Loc, error)
+	// Watch loc changes
+	WatchLocs(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchLocsClient, error)
+	// Set the requested state of a switch
+	SetSwitchRequest(ctx context.Context, in *v1.Switch, opts ...grpc.CallOption) (*v1.Empty, error)
+	// Get the state of the switch with the address of the given switch
+	GetSwitch(ctx context.Context, in *v1.Switch, opts ...grpc.CallOption) (*v1.Switch, error)
+	// Watch switch changes
+	WatchSwitches(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchSwitchesClient, error)
+	// Set the requested state of an output
+	SetOutputRequest(ctx context.Context, in *v1.Output, opts ...grpc.CallOption) (*v1.Empty, error)
+	// Get the state of the output with the address of the given output
+	GetOutput(ctx context.Context, in *v1.Output, opts ...grpc.CallOption) (*v1.Output, error)
+	// Watch output changes
+	WatchOutputs(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchOutputsClient, error)
+	// Get the state of the sensor with the address of the given sensor
+	GetSensor(ctx context.Context, in *v1.Sensor, opts ...grpc.CallOption) (*v1.Sensor, error)
+	// Watch sensor changes
+	WatchSensors(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchSensorsClient, error)
+	// Get the actual clock state
+	GetClock(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (*v1.Clock, error)
+	// Watch clock changes
+	WatchClock(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchClockClient, error)
+	// List all known local workers (with requested config & actual info).
+	// The stream ends after the last local worker has been sent.
+	ListWorkers(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (LayoutControlService_ListWorkersClient, error)
+	// Get the local worker with the ID of the given local worker
+	GetWorker(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*v1.LocalWorker, error)
+	// Get the liveness of the local worker with the ID of the given local worker
+	GetWorkerLiveness(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*WorkerLiveness, error)
+	// Watch liveness changes of local workers (online, stale & offline).
+	// The stream starts with the liveness of all known local workers.
+	WatchWorkerLiveness(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchWorkerLivenessClient, error)
+	// Request the local worker with the ID of the given local worker to reset itself
+	ResetWorker(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*v1.Empty, error)
+	// Discover the devices of the local worker with the ID of the given local worker
+	DiscoverWorker(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*v1.DiscoverResult, error)
+	// Get the revisions of the configuration of the local worker with the ID of the given local worker.
+	// Requires a configuration registry that keeps a history.
+	GetWorkerConfigHistory(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*WorkerConfigHistory, error)
+	// Configure a local worker with its configuration at an earlier revision.
+	// Requires a configuration registry that keeps a history.
+	RollbackWorkerConfig(ctx context.Context, in *RollbackWorkerConfigRequest, opts ...grpc.CallOption) (*v1.Empty, error)
+	// Force all local workers to reconfigure, even when their configuration has not changed.
+	// Requires a state store.
+	ReconfigureAllWorkers(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (*v1.Empty, error)
+	// List all objects of which the actual state differs from the requested state.
+	// The stream ends after the last object has been sent.
+	ListDivergentObjects(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (LayoutControlService_ListDivergentObjectsClient, error)
+}
+
+type layoutControlServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLayoutControlServiceClient(cc *grpc.ClientConn) LayoutControlServiceClient {
+	return &layoutControlServiceClient{cc}
+}
+
+func (c *layoutControlServiceClient) SetPowerRequest(ctx context.Context, in *v1.PowerState, opts ...grpc.CallOption) (*v1.Empty, error) {
+	out := new(v1.Empty)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/SetPowerRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) GetPower(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (*v1.Power, error) {
+	out := new(v1.Power)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/GetPower", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) WatchPower(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchPowerClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LayoutControlService_serviceDesc.Streams[0], "/binkynet.netmanager.v1.LayoutControlService/WatchPower", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &layoutControlServiceWatchPowerClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LayoutControlService_WatchPowerClient interface {
+	Recv() (*v1.Power, error)
+	grpc.ClientStream
+}
+
+type layoutControlServiceWatchPowerClient struct {
+	grpc.ClientStream
+}
+
+func (x *layoutControlServiceWatchPowerClient) Recv() (*v1.Power, error) {
+	m := new(v1.Power)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *layoutControlServiceClient) SetLocRequest(ctx context.Context, in *v1.Loc, opts ...grpc.CallOption) (*v1.Empty, error) {
+	out := new(v1.Empty)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/SetLocRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) GetLoc(ctx context.Context, in *v1.Loc, opts ...grpc.CallOption) (*v1.Loc, error) {
+	out := new(v1.Loc)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/GetLoc", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) WatchLocs(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchLocsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LayoutControlService_serviceDesc.Streams[1], "/binkynet.netmanager.v1.LayoutControlService/WatchLocs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &layoutControlServiceWatchLocsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LayoutControlService_WatchLocsClient interface {
+	Recv() (*v1.Loc, error)
+	grpc.ClientStream
+}
+
+type layoutControlServiceWatchLocsClient struct {
+	grpc.ClientStream
+}
+
+func (x *layoutControlServiceWatchLocsClient) Recv() (*v1.Loc, error) {
+	m := new(v1.Loc)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *layoutControlServiceClient) SetSwitchRequest(ctx context.Context, in *v1.Switch, opts ...grpc.CallOption) (*v1.Empty, error) {
+	out := new(v1.Empty)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/SetSwitchRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) GetSwitch(ctx context.Context, in *v1.Switch, opts ...grpc.CallOption) (*v1.Switch, error) {
+	out := new(v1.Switch)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/GetSwitch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) WatchSwitches(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchSwitchesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LayoutControlService_serviceDesc.Streams[2], "/binkynet.netmanager.v1.LayoutControlService/WatchSwitches", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &layoutControlServiceWatchSwitchesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LayoutControlService_WatchSwitchesClient interface {
+	Recv() (*v1.Switch, error)
+	grpc.ClientStream
+}
+
+type layoutControlServiceWatchSwitchesClient struct {
+	grpc.ClientStream
+}
+
+func (x *layoutControlServiceWatchSwitchesClient) Recv() (*v1.Switch, error) {
+	m := new(v1.Switch)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *layoutControlServiceClient) SetOutputRequest(ctx context.Context, in *v1.Output, opts ...grpc.CallOption) (*v1.Empty, error) {
+	out := new(v1.Empty)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/SetOutputRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) GetOutput(ctx context.Context, in *v1.Output, opts ...grpc.CallOption) (*v1.Output, error) {
+	out := new(v1.Output)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/GetOutput", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) WatchOutputs(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchOutputsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LayoutControlService_serviceDesc.Streams[3], "/binkynet.netmanager.v1.LayoutControlService/WatchOutputs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &layoutControlServiceWatchOutputsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LayoutControlService_WatchOutputsClient interface {
+	Recv() (*v1.Output, error)
+	grpc.ClientStream
+}
+
+type layoutControlServiceWatchOutputsClient struct {
+	grpc.ClientStream
+}
+
+func (x *layoutControlServiceWatchOutputsClient) Recv() (*v1.Output, error) {
+	m := new(v1.Output)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *layoutControlServiceClient) GetSensor(ctx context.Context, in *v1.Sensor, opts ...grpc.CallOption) (*v1.Sensor, error) {
+	out := new(v1.Sensor)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/GetSensor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) WatchSensors(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchSensorsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LayoutControlService_serviceDesc.Streams[4], "/binkynet.netmanager.v1.LayoutControlService/WatchSensors", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &layoutControlServiceWatchSensorsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LayoutControlService_WatchSensorsClient interface {
+	Recv() (*v1.Sensor, error)
+	grpc.ClientStream
+}
+
+type layoutControlServiceWatchSensorsClient struct {
+	grpc.ClientStream
+}
+
+func (x *layoutControlServiceWatchSensorsClient) Recv() (*v1.Sensor, error) {
+	m := new(v1.Sensor)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *layoutControlServiceClient) GetClock(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (*v1.Clock, error) {
+	out := new(v1.Clock)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/GetClock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) WatchClock(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchClockClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LayoutControlService_serviceDesc.Streams[5], "/binkynet.netmanager.v1.LayoutControlService/WatchClock", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &layoutControlServiceWatchClockClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LayoutControlService_WatchClockClient interface {
+	Recv() (*v1.Clock, error)
+	grpc.ClientStream
+}
+
+type layoutControlServiceWatchClockClient struct {
+	grpc.ClientStream
+}
+
+func (x *layoutControlServiceWatchClockClient) Recv() (*v1.Clock, error) {
+	m := new(v1.Clock)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *layoutControlServiceClient) ListWorkers(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (LayoutControlService_ListWorkersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LayoutControlService_serviceDesc.Streams[6], "/binkynet.netmanager.v1.LayoutControlService/ListWorkers", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &layoutControlServiceListWorkersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LayoutControlService_ListWorkersClient interface {
+	Recv() (*v1.LocalWorker, error)
+	grpc.ClientStream
+}
+
+type layoutControlServiceListWorkersClient struct {
+	grpc.ClientStream
+}
+
+func (x *layoutControlServiceListWorkersClient) Recv() (*v1.LocalWorker, error) {
+	m := new(v1.LocalWorker)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *layoutControlServiceClient) GetWorker(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*v1.LocalWorker, error) {
+	out := new(v1.LocalWorker)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/GetWorker", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) GetWorkerLiveness(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*WorkerLiveness, error) {
+	out := new(WorkerLiveness)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/GetWorkerLiveness", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) WatchWorkerLiveness(ctx context.Context, in *v1.WatchOptions, opts ...grpc.CallOption) (LayoutControlService_WatchWorkerLivenessClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LayoutControlService_serviceDesc.Streams[7], "/binkynet.netmanager.v1.LayoutControlService/WatchWorkerLiveness", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &layoutControlServiceWatchWorkerLivenessClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LayoutControlService_WatchWorkerLivenessClient interface {
+	Recv() (*WorkerLiveness, error)
+	grpc.ClientStream
+}
+
+type layoutControlServiceWatchWorkerLivenessClient struct {
+	grpc.ClientStream
+}
+
+func (x *layoutControlServiceWatchWorkerLivenessClient) Recv() (*WorkerLiveness, error) {
+	m := new(WorkerLiveness)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *layoutControlServiceClient) ResetWorker(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*v1.Empty, error) {
+	out := new(v1.Empty)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/ResetWorker", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) DiscoverWorker(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*v1.DiscoverResult, error) {
+	out := new(v1.DiscoverResult)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/DiscoverWorker", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) GetWorkerConfigHistory(ctx context.Context, in *v1.LocalWorker, opts ...grpc.CallOption) (*WorkerConfigHistory, error) {
+	out := new(WorkerConfigHistory)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/GetWorkerConfigHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) RollbackWorkerConfig(ctx context.Context, in *RollbackWorkerConfigRequest, opts ...grpc.CallOption) (*v1.Empty, error) {
+	out := new(v1.Empty)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/RollbackWorkerConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) ReconfigureAllWorkers(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (*v1.Empty, error) {
+	out := new(v1.Empty)
+	err := c.cc.Invoke(ctx, "/binkynet.netmanager.v1.LayoutControlService/ReconfigureAllWorkers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *layoutControlServiceClient) ListDivergentObjects(ctx context.Context, in *v1.Empty, opts ...grpc.CallOption) (LayoutControlService_ListDivergentObjectsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_LayoutControlService_serviceDesc.Streams[8], "/binkynet.netmanager.v1.LayoutControlService/ListDivergentObjects", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &layoutControlServiceListDivergentObjectsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LayoutControlService_ListDivergentObjectsClient interface {
+	Recv() (*DivergentObject, error)
+	grpc.ClientStream
+}
+
+type layoutControlServiceListDivergentObjectsClient struct {
+	grpc.ClientStream
+}
+
+func (x *layoutControlServiceListDivergentObjectsClient) Recv() (*DivergentObject, error) {
+	m := new(DivergentObject)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LayoutControlServiceServer is the server API for LayoutControlService service.
+type LayoutControlServiceServer interface {
+	// Set the requested power state
+	SetPowerRequest(context.Context, *v1.PowerState) (*v1.Empty, error)
+	// Get the requested & actual power state
+	GetPower(context.Context, *v1.Empty) (*v1.Power, error)
+	// Watch power changes
+	WatchPower(*v1.WatchOptions, LayoutControlService_WatchPowerServer) error
+	// Set the requested state of a loc
+	SetLocRequest(context.Context, *v1.Loc) (*v1.Empty, error)
+	// Get the state of the loc with the address of the given loc
+	GetLoc(context.Context, *v1.Loc) (*v1.Loc, error)
+	// Watch loc changes
+	WatchLocs(*v1.WatchOptions, LayoutControlService_WatchLocsServer) error
+	// Set the requested state of a switch
+	SetSwitchRequest(context.Context, *v1.Switch) (*v1.Empty, error)
+	// Get the state of the switch with the address of the given switch
+	GetSwitch(context.Context, *v1.Switch) (*v1.Switch, error)
+	// Watch switch changes
+	WatchSwitches(*v1.WatchOptions, LayoutControlService_WatchSwitchesServer) error
+	// Set the requested state of an output
+	SetOutputRequest(context.Context, *v1.Output) (*v1.Empty, error)
+	// Get the state of the output with the address of the given output
+	GetOutput(context.Context, *v1.Output) (*v1.Output, error)
+	// Watch output changes
+	WatchOutputs(*v1.WatchOptions, LayoutControlService_WatchOutputsServer) error
+	// Get the state of the sensor with the address of the given sensor
+	GetSensor(context.Context, *v1.Sensor) (*v1.Sensor, error)
+	// Watch sensor changes
+	WatchSensors(*v1.WatchOptions, LayoutControlService_WatchSensorsServer) error
+	// Get the actual clock state
+	GetClock(context.Context, *v1.Empty) (*v1.Clock, error)
+	// Watch clock changes
+	WatchClock(*v1.WatchOptions, LayoutControlService_WatchClockServer) error
+	// List all known local workers (with requested config & actual info).
+	// The stream ends after the last local worker has been sent.
+	ListWorkers(*v1.Empty, LayoutControlService_ListWorkersServer) error
+	// Get the local worker with the ID of the given local worker
+	GetWorker(context.Context, *v1.LocalWorker) (*v1.LocalWorker, error)
+	// Get the liveness of the local worker with the ID of the given local worker
+	GetWorkerLiveness(context.Context, *v1.LocalWorker) (*WorkerLiveness, error)
+	// Watch liveness changes of local workers (online, stale & offline).
+	// The stream starts with the liveness of all known local workers.
+	WatchWorkerLiveness(*v1.WatchOptions, LayoutControlService_WatchWorkerLivenessServer) error
+	// Request the local worker with the ID of the given local worker to reset itself
+	ResetWorker(context.Context, *v1.LocalWorker) (*v1.Empty, error)
+	// Discover the devices of the local worker with the ID of the given local worker
+	DiscoverWorker(context.Context, *v1.LocalWorker) (*v1.DiscoverResult, error)
+	// Get the revisions of the configuration of the local worker with the ID of the given local worker.
+	// Requires a configuration registry that keeps a history.
+	GetWorkerConfigHistory(context.Context, *v1.LocalWorker) (*WorkerConfigHistory, error)
+	// Configure a local worker with its configuration at an earlier revision.
+	// Requires a configuration registry that keeps a history.
+	RollbackWorkerConfig(context.Context, *RollbackWorkerConfigRequest) (*v1.Empty, error)
+	// Force all local workers to reconfigure, even when their configuration has not changed.
+	// Requires a state store.
+	ReconfigureAllWorkers(context.Context, *v1.Empty) (*v1.Empty, error)
+	// List all objects of which the actual state differs from the requested state.
+	// The stream ends after the last object has been sent.
+	ListDivergentObjects(*v1.Empty, LayoutControlService_ListDivergentObjectsServer) error
+}
+
+// UnimplementedLayoutControlServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedLayoutControlServiceServer struct {
+}
+
+func (*UnimplementedLayoutControlServiceServer) SetPowerRequest(ctx context.Context, req *v1.PowerState) (*v1.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPowerRequest not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) GetPower(ctx context.Context, req *v1.Empty) (*v1.Power, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPower not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) WatchPower(req *v1.WatchOptions, srv LayoutControlService_WatchPowerServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchPower not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) SetLocRequest(ctx context.Context, req *v1.Loc) (*v1.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLocRequest not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) GetLoc(ctx context.Context, req *v1.Loc) (*v1.Loc, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoc not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) WatchLocs(req *v1.WatchOptions, srv LayoutControlService_WatchLocsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchLocs not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) SetSwitchRequest(ctx context.Context, req *v1.Switch) (*v1.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSwitchRequest not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) GetSwitch(ctx context.Context, req *v1.Switch) (*v1.Switch, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSwitch not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) WatchSwitches(req *v1.WatchOptions, srv LayoutControlService_WatchSwitchesServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSwitches not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) SetOutputRequest(ctx context.Context, req *v1.Output) (*v1.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOutputRequest not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) GetOutput(ctx context.Context, req *v1.Output) (*v1.Output, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOutput not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) WatchOutputs(req *v1.WatchOptions, srv LayoutControlService_WatchOutputsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOutputs not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) GetSensor(ctx context.Context, req *v1.Sensor) (*v1.Sensor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSensor not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) WatchSensors(req *v1.WatchOptions, srv LayoutControlService_WatchSensorsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSensors not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) GetClock(ctx context.Context, req *v1.Empty) (*v1.Clock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClock not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) WatchClock(req *v1.WatchOptions, srv LayoutControlService_WatchClockServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchClock not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) ListWorkers(req *v1.Empty, srv LayoutControlService_ListWorkersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListWorkers not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) GetWorker(ctx context.Context, req *v1.LocalWorker) (*v1.LocalWorker, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorker not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) GetWorkerLiveness(ctx context.Context, req *v1.LocalWorker) (*WorkerLiveness, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorkerLiveness not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) WatchWorkerLiveness(req *v1.WatchOptions, srv LayoutControlService_WatchWorkerLivenessServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchWorkerLiveness not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) ResetWorker(ctx context.Context, req *v1.LocalWorker) (*v1.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetWorker not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) DiscoverWorker(ctx context.Context, req *v1.LocalWorker) (*v1.DiscoverResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiscoverWorker not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) GetWorkerConfigHistory(ctx context.Context, req *v1.LocalWorker) (*WorkerConfigHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorkerConfigHistory not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) RollbackWorkerConfig(ctx context.Context, req *RollbackWorkerConfigRequest) (*v1.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackWorkerConfig not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) ReconfigureAllWorkers(ctx context.Context, req *v1.Empty) (*v1.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReconfigureAllWorkers not implemented")
+}
+func (*UnimplementedLayoutControlServiceServer) ListDivergentObjects(req *v1.Empty, srv LayoutControlService_ListDivergentObjectsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListDivergentObjects not implemented")
+}
+
+func RegisterLayoutControlServiceServer(s *grpc.Server, srv LayoutControlServiceServer) {
+	s.RegisterService(&_LayoutControlService_serviceDesc, srv)
+}
+
+func _LayoutControlService_SetPowerRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.PowerState)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).SetPowerRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/SetPowerRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).SetPowerRequest(ctx, req.(*v1.PowerState))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_GetPower_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).GetPower(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/GetPower",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).GetPower(ctx, req.(*v1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_WatchPower_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.WatchOptions)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LayoutControlServiceServer).WatchPower(m, &layoutControlServiceWatchPowerServer{stream})
+}
+
+type LayoutControlService_WatchPowerServer interface {
+	Send(*v1.Power) error
+	grpc.ServerStream
+}
+
+type layoutControlServiceWatchPowerServer struct {
+	grpc.ServerStream
+}
+
+func (x *layoutControlServiceWatchPowerServer) Send(m *v1.Power) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LayoutControlService_SetLocRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Loc)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).SetLocRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/SetLocRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).SetLocRequest(ctx, req.(*v1.Loc))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_GetLoc_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Loc)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).GetLoc(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/GetLoc",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).GetLoc(ctx, req.(*v1.Loc))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_WatchLocs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.WatchOptions)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LayoutControlServiceServer).WatchLocs(m, &layoutControlServiceWatchLocsServer{stream})
+}
+
+type LayoutControlService_WatchLocsServer interface {
+	Send(*v1.Loc) error
+	grpc.ServerStream
+}
+
+type layoutControlServiceWatchLocsServer struct {
+	grpc.ServerStream
+}
+
+func (x *layoutControlServiceWatchLocsServer) Send(m *v1.Loc) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LayoutControlService_SetSwitchRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Switch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).SetSwitchRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/SetSwitchRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).SetSwitchRequest(ctx, req.(*v1.Switch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_GetSwitch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Switch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).GetSwitch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/GetSwitch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).GetSwitch(ctx, req.(*v1.Switch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_WatchSwitches_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.WatchOptions)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LayoutControlServiceServer).WatchSwitches(m, &layoutControlServiceWatchSwitchesServer{stream})
+}
+
+type LayoutControlService_WatchSwitchesServer interface {
+	Send(*v1.Switch) error
+	grpc.ServerStream
+}
+
+type layoutControlServiceWatchSwitchesServer struct {
+	grpc.ServerStream
+}
+
+func (x *layoutControlServiceWatchSwitchesServer) Send(m *v1.Switch) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LayoutControlService_SetOutputRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Output)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).SetOutputRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/SetOutputRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).SetOutputRequest(ctx, req.(*v1.Output))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_GetOutput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Output)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).GetOutput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/GetOutput",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).GetOutput(ctx, req.(*v1.Output))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_WatchOutputs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.WatchOptions)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LayoutControlServiceServer).WatchOutputs(m, &layoutControlServiceWatchOutputsServer{stream})
+}
+
+type LayoutControlService_WatchOutputsServer interface {
+	Send(*v1.Output) error
+	grpc.ServerStream
+}
+
+type layoutControlServiceWatchOutputsServer struct {
+	grpc.ServerStream
+}
+
+func (x *layoutControlServiceWatchOutputsServer) Send(m *v1.Output) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LayoutControlService_GetSensor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Sensor)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).GetSensor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/GetSensor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).GetSensor(ctx, req.(*v1.Sensor))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_WatchSensors_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.WatchOptions)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LayoutControlServiceServer).WatchSensors(m, &layoutControlServiceWatchSensorsServer{stream})
+}
+
+type LayoutControlService_WatchSensorsServer interface {
+	Send(*v1.Sensor) error
+	grpc.ServerStream
+}
+
+type layoutControlServiceWatchSensorsServer struct {
+	grpc.ServerStream
+}
+
+func (x *layoutControlServiceWatchSensorsServer) Send(m *v1.Sensor) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LayoutControlService_GetClock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).GetClock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/GetClock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).GetClock(ctx, req.(*v1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_WatchClock_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.WatchOptions)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LayoutControlServiceServer).WatchClock(m, &layoutControlServiceWatchClockServer{stream})
+}
+
+type LayoutControlService_WatchClockServer interface {
+	Send(*v1.Clock) error
+	grpc.ServerStream
+}
+
+type layoutControlServiceWatchClockServer struct {
+	grpc.ServerStream
+}
+
+func (x *layoutControlServiceWatchClockServer) Send(m *v1.Clock) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LayoutControlService_ListWorkers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LayoutControlServiceServer).ListWorkers(m, &layoutControlServiceListWorkersServer{stream})
+}
+
+type LayoutControlService_ListWorkersServer interface {
+	Send(*v1.LocalWorker) error
+	grpc.ServerStream
+}
+
+type layoutControlServiceListWorkersServer struct {
+	grpc.ServerStream
+}
+
+func (x *layoutControlServiceListWorkersServer) Send(m *v1.LocalWorker) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LayoutControlService_GetWorker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.LocalWorker)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).GetWorker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/GetWorker",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).GetWorker(ctx, req.(*v1.LocalWorker))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_GetWorkerLiveness_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.LocalWorker)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).GetWorkerLiveness(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/GetWorkerLiveness",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).GetWorkerLiveness(ctx, req.(*v1.LocalWorker))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_WatchWorkerLiveness_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.WatchOptions)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LayoutControlServiceServer).WatchWorkerLiveness(m, &layoutControlServiceWatchWorkerLivenessServer{stream})
+}
+
+type LayoutControlService_WatchWorkerLivenessServer interface {
+	Send(*WorkerLiveness) error
+	grpc.ServerStream
+}
+
+type layoutControlServiceWatchWorkerLivenessServer struct {
+	grpc.ServerStream
+}
+
+func (x *layoutControlServiceWatchWorkerLivenessServer) Send(m *WorkerLiveness) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LayoutControlService_ResetWorker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.LocalWorker)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).ResetWorker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/ResetWorker",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).ResetWorker(ctx, req.(*v1.LocalWorker))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_DiscoverWorker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.LocalWorker)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).DiscoverWorker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/DiscoverWorker",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).DiscoverWorker(ctx, req.(*v1.LocalWorker))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_GetWorkerConfigHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.LocalWorker)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).GetWorkerConfigHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/GetWorkerConfigHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).GetWorkerConfigHistory(ctx, req.(*v1.LocalWorker))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_RollbackWorkerConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackWorkerConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).RollbackWorkerConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/RollbackWorkerConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).RollbackWorkerConfig(ctx, req.(*RollbackWorkerConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_ReconfigureAllWorkers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LayoutControlServiceServer).ReconfigureAllWorkers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/binkynet.netmanager.v1.LayoutControlService/ReconfigureAllWorkers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LayoutControlServiceServer).ReconfigureAllWorkers(ctx, req.(*v1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LayoutControlService_ListDivergentObjects_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(v1.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LayoutControlServiceServer).ListDivergentObjects(m, &layoutControlServiceListDivergentObjectsServer{stream})
+}
+
+type LayoutControlService_ListDivergentObjectsServer interface {
+	Send(*DivergentObject) error
+	grpc.ServerStream
+}
+
+type layoutControlServiceListDivergentObjectsServer struct {
+	grpc.ServerStream
+}
+
+func (x *layoutControlServiceListDivergentObjectsServer) Send(m *DivergentObject) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _LayoutControlService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "binkynet.netmanager.v1.LayoutControlService",
+	HandlerType: (*LayoutControlServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetPowerRequest",
+			Handler:    _LayoutControlService_SetPowerRequest_Handler,
+		},
+		{
+			MethodName: "GetPower",
+			Handler:    _LayoutControlService_GetPower_Handler,
+		},
+		{
+			MethodName: "SetLocRequest",
+			Handler:    _LayoutControlService_SetLocRequest_Handler,
+		},
+		{
+			MethodName: "GetLoc",
+			Handler:    _LayoutControlService_GetLoc_Handler,
+		},
+		{
+			MethodName: "SetSwitchRequest",
+			Handler:    _LayoutControlService_SetSwitchRequest_Handler,
+		},
+		{
+			MethodName: "GetSwitch",
+			Handler:    _LayoutControlService_GetSwitch_Handler,
+		},
+		{
+			MethodName: "SetOutputRequest",
+			Handler:    _LayoutControlService_SetOutputRequest_Handler,
+		},
+		{
+			MethodName: "GetOutput",
+			Handler:    _LayoutControlService_GetOutput_Handler,
+		},
+		{
+			MethodName: "GetSensor",
+			Handler:    _LayoutControlService_GetSensor_Handler,
+		},
+		{
+			MethodName: "GetClock",
+			Handler:    _LayoutControlService_GetClock_Handler,
+		},
+		{
+			MethodName: "GetWorker",
+			Handler:    _LayoutControlService_GetWorker_Handler,
+		},
+		{
+			MethodName: "GetWorkerLiveness",
+			Handler:    _LayoutControlService_GetWorkerLiveness_Handler,
+		},
+		{
+			MethodName: "ResetWorker",
+			Handler:    _LayoutControlService_ResetWorker_Handler,
+		},
+		{
+			MethodName: "DiscoverWorker",
+			Handler:    _LayoutControlService_DiscoverWorker_Handler,
+		},
+		{
+			MethodName: "GetWorkerConfigHistory",
+			Handler:    _LayoutControlService_GetWorkerConfigHistory_Handler,
+		},
+		{
+			MethodName: "RollbackWorkerConfig",
+			Handler:    _LayoutControlService_RollbackWorkerConfig_Handler,
+		},
+		{
+			MethodName: "ReconfigureAllWorkers",
+			Handler:    _LayoutControlService_ReconfigureAllWorkers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPower",
+			Handler:       _LayoutControlService_WatchPower_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchLocs",
+			Handler:       _LayoutControlService_WatchLocs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchSwitches",
+			Handler:       _LayoutControlService_WatchSwitches_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchOutputs",
+			Handler:       _LayoutControlService_WatchOutputs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchSensors",
+			Handler:       _LayoutControlService_WatchSensors_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchClock",
+			Handler:       _LayoutControlService_WatchClock_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListWorkers",
+			Handler:       _LayoutControlService_ListWorkers_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchWorkerLiveness",
+			Handler:       _LayoutControlService_WatchWorkerLiveness_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListDivergentObjects",
+			Handler:       _LayoutControlService_ListDivergentObjects_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "layout_control.proto",
+}
+
+func (m *WorkerLiveness) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WorkerLiveness) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *WorkerLiveness) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.LastUpdatedUnixtime != 0 {
+		i = encodeVarintLayoutControl(dAtA, i, uint64(m.LastUpdatedUnixtime))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Liveness) > 0 {
+		i -= len(m.Liveness)
+		copy(dAtA[i:], m.Liveness)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Liveness)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Id) > 0 {
+		i -= len(m.Id)
+		copy(dAtA[i:], m.Id)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Id)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *WorkerConfigHistory) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WorkerConfigHistory) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *WorkerConfigHistory) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Revisions) > 0 {
+		for iNdEx := len(m.Revisions) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Revisions[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintLayoutControl(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Id) > 0 {
+		i -= len(m.Id)
+		copy(dAtA[i:], m.Id)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Id)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *WorkerConfigRevision) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *WorkerConfigRevision) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *WorkerConfigRevision) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Active {
+		i--
+		if m.Active {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.Subject) > 0 {
+		i -= len(m.Subject)
+		copy(dAtA[i:], m.Subject)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Subject)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Author) > 0 {
+		i -= len(m.Author)
+		copy(dAtA[i:], m.Author)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Author)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Unixtime != 0 {
+		i = encodeVarintLayoutControl(dAtA, i, uint64(m.Unixtime))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Hash) > 0 {
+		i -= len(m.Hash)
+		copy(dAtA[i:], m.Hash)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Hash)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *RollbackWorkerConfigRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RollbackWorkerConfigRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RollbackWorkerConfigRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Revision) > 0 {
+		i -= len(m.Revision)
+		copy(dAtA[i:], m.Revision)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Revision)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Id) > 0 {
+		i -= len(m.Id)
+		copy(dAtA[i:], m.Id)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Id)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DivergentObject) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DivergentObject) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DivergentObject) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.NextAttemptUnixtime != 0 {
+		i = encodeVarintLayoutControl(dAtA, i, uint64(m.NextAttemptUnixtime))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Attempts != 0 {
+		i = encodeVarintLayoutControl(dAtA, i, uint64(m.Attempts))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.SinceUnixtime != 0 {
+		i = encodeVarintLayoutControl(dAtA, i, uint64(m.SinceUnixtime))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Address) > 0 {
+		i -= len(m.Address)
+		copy(dAtA[i:], m.Address)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Address)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Domain) > 0 {
+		i -= len(m.Domain)
+		copy(dAtA[i:], m.Domain)
+		i = encodeVarintLayoutControl(dAtA, i, uint64(len(m.Domain)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintLayoutControl(dAtA []byte, offset int, v uint64) int {
+	offset -= sovLayoutControl(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *WorkerLiveness) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	l = len(m.Liveness)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	if m.LastUpdatedUnixtime != 0 {
+		n += 1 + sovLayoutControl(uint64(m.LastUpdatedUnixtime))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *WorkerConfigHistory) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	if len(m.Revisions) > 0 {
+		for _, e := range m.Revisions {
+			l = e.Size()
+			n += 1 + l + sovLayoutControl(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *WorkerConfigRevision) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Hash)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	if m.Unixtime != 0 {
+		n += 1 + sovLayoutControl(uint64(m.Unixtime))
+	}
+	l = len(m.Author)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	l = len(m.Subject)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	if m.Active {
+		n += 2
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *RollbackWorkerConfigRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Id)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	l = len(m.Revision)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DivergentObject) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Domain)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	l = len(m.Address)
+	if l > 0 {
+		n += 1 + l + sovLayoutControl(uint64(l))
+	}
+	if m.SinceUnixtime != 0 {
+		n += 1 + sovLayoutControl(uint64(m.SinceUnixtime))
+	}
+	if m.Attempts != 0 {
+		n += 1 + sovLayoutControl(uint64(m.Attempts))
+	}
+	if m.NextAttemptUnixtime != 0 {
+		n += 1 + sovLayoutControl(uint64(m.NextAttemptUnixtime))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovLayoutControl(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozLayoutControl(x uint64) (n int) {
+	return sovLayoutControl(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *WorkerLiveness) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLayoutControl
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WorkerLiveness: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WorkerLiveness: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Liveness", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Liveness = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastUpdatedUnixtime", wireType)
+			}
+			m.LastUpdatedUnixtime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LastUpdatedUnixtime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLayoutControl(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WorkerConfigHistory) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLayoutControl
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WorkerConfigHistory: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WorkerConfigHistory: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Revisions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Revisions = append(m.Revisions, &WorkerConfigRevision{})
+			if err := m.Revisions[len(m.Revisions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLayoutControl(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WorkerConfigRevision) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLayoutControl
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WorkerConfigRevision: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WorkerConfigRevision: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Hash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Unixtime", wireType)
+			}
+			m.Unixtime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Unixtime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Author", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Author = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Subject", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Subject = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Active", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Active = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLayoutControl(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *RollbackWorkerConfigRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLayoutControl
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RollbackWorkerConfigRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RollbackWorkerConfigRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Id", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Id = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Revision", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Revision = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLayoutControl(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DivergentObject) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowLayoutControl
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DivergentObject: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DivergentObject: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Domain", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Domain = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Address", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Address = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SinceUnixtime", wireType)
+			}
+			m.SinceUnixtime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SinceUnixtime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Attempts", wireType)
+			}
+			m.Attempts = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Attempts |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NextAttemptUnixtime", wireType)
+			}
+			m.NextAttemptUnixtime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NextAttemptUnixtime |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipLayoutControl(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthLayoutControl
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipLayoutControl(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowLayoutControl
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowLayoutControl
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthLayoutControl
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupLayoutControl
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthLayoutControl
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthLayoutControl        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowLayoutControl          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupLayoutControl = fmt.Errorf("proto: unexpected end of group")
+)
@@ -1,4 +1,4 @@
-//    Copyright 2023 Ewout Prangsma
+//    Copyright 2024 Ewout Prangsma
 //
 //    Licensed under the Apache License, Version 2.0 (the "License");
 //    you may not use this file except in compliance with the License.
@@ -12,23 +12,25 @@
 //    See the License for the specific language governing permissions and
 //    limitations under the License.
 
-package manager
+package gateway
 
 import (
-	"context"
-	"time"
+	"embed"
+	"io/fs"
+	"net/http"
 )
 
-// getTimeout extracts the timeout from the given context and returns it.
-// If no timeout is found, the default timeout is returned.
-func getTimeout(ctx context.Context, defaultTimeout time.Duration) time.Duration {
-	deadline, ok := ctx.Deadline()
-	if !ok {
-		return defaultTimeout
-	}
-	result := time.Until(deadline)
-	if result < 0 {
-		return 0
+// The dashboard is a static web page using the REST/JSON & WebSocket API.
+//
+//go:embed web
+var webFS embed.FS
+
+// newDashboardHandler creates a handler serving the files of the dashboard.
+func newDashboardHandler() http.Handler {
+	files, err := fs.Sub(webFS, "web")
+	if err != nil {
+		// Cannot happen, the folder is embedded
+		panic(err)
 	}
-	return result
+	return http.FileServer(http.FS(files))
 }
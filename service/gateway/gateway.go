@@ -0,0 +1,162 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package gateway contains an HTTP server offering a REST/JSON & WebSocket
+// API on top of the manager, for clients that cannot use GRPC.
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/binkynet/NetManager/service/manager"
+)
+
+// Gateway is an HTTP server offering the REST/JSON & WebSocket API.
+type Gateway interface {
+	// Run the gateway until the given context is cancelled.
+	Run(ctx context.Context) error
+}
+
+// Config of the gateway.
+type Config struct {
+	Host string
+	Port int
+	// TLS configuration used to serve the gateway (nil to serve plain HTTP)
+	TLSConfig *tls.Config
+}
+
+// Dependencies of the gateway.
+type Dependencies struct {
+	Log     zerolog.Logger
+	Manager manager.Manager
+}
+
+// New creates a new gateway.
+func New(conf Config, deps Dependencies) (Gateway, error) {
+	deps.Log = deps.Log.With().Str("component", "gateway").Logger()
+	return &gateway{
+		Config:       conf,
+		Dependencies: deps,
+	}, nil
+}
+
+type gateway struct {
+	Config
+	Dependencies
+}
+
+// Run the gateway until the given context is cancelled.
+func (g *gateway) Run(ctx context.Context) error {
+	addr := net.JoinHostPort(g.Host, strconv.Itoa(g.Port))
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           g.newHandler(),
+		ReadHeaderTimeout: time.Second * 10,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	if g.TLSConfig != nil {
+		lis = tls.NewListener(lis, g.TLSConfig.Clone())
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			g.Log.Warn().Err(err).Msg("Gateway did not close gracefully, stopping with force...")
+			srv.Close()
+		}
+	}()
+	g.Log.Info().Str("address", addr).Bool("secure", g.TLSConfig != nil).Msg("Serving gateway")
+	if err := srv.Serve(lis); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// newHandler creates the handler for all routes of the gateway.
+func (g *gateway) newHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	// Local workers
+	mux.HandleFunc("GET /api/v1/workers", g.handleGetWorkers)
+	mux.HandleFunc("GET /api/v1/workers/{id}", g.handleGetWorker)
+	mux.HandleFunc("POST /api/v1/workers/reconfigure", g.handleReconfigureWorkers)
+	mux.HandleFunc("POST /api/v1/workers/{id}/reset", g.handleResetWorker)
+	mux.HandleFunc("POST /api/v1/workers/{id}/discover", g.handleDiscoverWorker)
+	mux.HandleFunc("GET /api/v1/workers/{id}/history", g.handleGetWorkerHistory)
+	mux.HandleFunc("POST /api/v1/workers/{id}/rollback", g.handleRollbackWorker)
+
+	// Objects
+	mux.HandleFunc("GET /api/v1/power", g.handleGetPower)
+	mux.HandleFunc("PUT /api/v1/power", g.handleSetPower)
+	mux.HandleFunc("GET /api/v1/locs", g.handleGetLocs)
+	mux.HandleFunc("GET /api/v1/locs/{module}/{local}", g.handleGetLoc)
+	mux.HandleFunc("PUT /api/v1/locs/{module}/{local}", g.handleSetLoc)
+	mux.HandleFunc("GET /api/v1/switches", g.handleGetSwitches)
+	mux.HandleFunc("GET /api/v1/switches/{module}/{local}", g.handleGetSwitch)
+	mux.HandleFunc("PUT /api/v1/switches/{module}/{local}", g.handleSetSwitch)
+	mux.HandleFunc("GET /api/v1/outputs", g.handleGetOutputs)
+	mux.HandleFunc("GET /api/v1/outputs/{module}/{local}", g.handleGetOutput)
+	mux.HandleFunc("PUT /api/v1/outputs/{module}/{local}", g.handleSetOutput)
+	mux.HandleFunc("GET /api/v1/sensors", g.handleGetSensors)
+	mux.HandleFunc("GET /api/v1/sensors/{module}/{local}", g.handleGetSensor)
+	mux.HandleFunc("GET /api/v1/clock", g.handleGetClock)
+	mux.HandleFunc("GET /api/v1/divergent", g.handleGetDivergent)
+
+	// Events
+	mux.HandleFunc("GET /api/v1/watch/workers", g.handleWatchWorkers)
+	mux.HandleFunc("GET /api/v1/watch/liveness", g.handleWatchLiveness)
+	mux.HandleFunc("GET /api/v1/watch/power", g.handleWatchPower)
+	mux.HandleFunc("GET /api/v1/watch/locs", g.handleWatchLocs)
+	mux.HandleFunc("GET /api/v1/watch/switches", g.handleWatchSwitches)
+	mux.HandleFunc("GET /api/v1/watch/outputs", g.handleWatchOutputs)
+	mux.HandleFunc("GET /api/v1/watch/sensors", g.handleWatchSensors)
+	mux.HandleFunc("GET /api/v1/watch/clock", g.handleWatchClock)
+
+	// Dashboard
+	mux.Handle("GET /", newDashboardHandler())
+
+	return requireJSON(mux)
+}
+
+// requireJSON refuses requests that can change state (all requests other
+// than GET & HEAD) unless their content type is JSON.
+// Browsers do not send such requests cross-origin without a CORS preflight,
+// which the gateway does not allow, so other sites cannot use the visitor's
+// browser to control the layout.
+func requireJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType,
+					fmt.Errorf("content type must be application/json, got '%s'", r.Header.Get("Content-Type")))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
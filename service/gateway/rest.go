@@ -0,0 +1,436 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/binkynet/NetManager/service/config"
+	"github.com/binkynet/NetManager/service/manager"
+)
+
+const (
+	// Maximum time to wait for the result of a discovery
+	discoverTimeout = time.Second * 30
+	// Maximum time to wait for a local worker to accept a reset request
+	resetTimeout = time.Second * 10
+)
+
+var (
+	marshaler   = jsonpb.Marshaler{OrigName: true, EmitDefaults: true}
+	unmarshaler = jsonpb.Unmarshaler{}
+)
+
+// workerResponse is the response of a single local worker.
+type workerResponse struct {
+	// Last known info (LocalWorkerInfo)
+	Info json.RawMessage `json:"info"`
+	// Alias of the requested configuration
+	Alias string `json:"alias,omitempty"`
+	// Hash of the requested configuration
+	RequestedConfigHash string    `json:"requested_config_hash,omitempty"`
+	RemoteAddress       string    `json:"remote_address,omitempty"`
+	LastUpdatedAt       time.Time `json:"last_updated_at"`
+	// Liveness (online, stale or offline)
+	Liveness string `json:"liveness"`
+}
+
+// GET /api/v1/workers
+func (g *gateway) handleGetWorkers(w http.ResponseWriter, r *http.Request) {
+	list := g.Manager.GetAllLocalWorkers()
+	sort.Slice(list, func(i, j int) bool { return list[i].GetId() < list[j].GetId() })
+	result := make([]workerResponse, 0, len(list))
+	for _, x := range list {
+		if resp, found, err := g.getWorker(x.GetId()); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		} else if found {
+			result = append(result, resp)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// GET /api/v1/workers/{id}
+func (g *gateway) handleGetWorker(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	resp, found, err := g.getWorker(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	} else if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("local worker '%s' not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// getWorker builds the response for the local worker with given ID.
+func (g *gateway) getWorker(id string) (workerResponse, bool, error) {
+	info, remoteAddr, lastUpdatedAt, found := g.Manager.GetLocalWorkerInfo(id)
+	if !found {
+		return workerResponse{}, false, nil
+	}
+	encoded, err := marshaler.MarshalToString(&info)
+	if err != nil {
+		return workerResponse{}, false, err
+	}
+	conf, _ := g.Manager.GetLocalWorkerConfig(id)
+	liveness, _ := g.Manager.GetLocalWorkerLiveness(id)
+	return workerResponse{
+		Info:                json.RawMessage(encoded),
+		Alias:               conf.GetAlias(),
+		RequestedConfigHash: conf.GetHash(),
+		RemoteAddress:       remoteAddr,
+		LastUpdatedAt:       lastUpdatedAt,
+		Liveness:            string(liveness),
+	}, true, nil
+}
+
+// POST /api/v1/workers/reconfigure
+func (g *gateway) handleReconfigureWorkers(w http.ResponseWriter, r *http.Request) {
+	if err := g.Manager.ReconfigureAllLocalWorkers(r.Context()); errors.Is(err, manager.ErrNoStateStore) {
+		writeError(w, http.StatusNotImplemented, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/v1/workers/{id}/reset
+func (g *gateway) handleResetWorker(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, _, _, found := g.Manager.GetLocalWorkerInfo(id); !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("local worker '%s' not found", id))
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), resetTimeout)
+	defer cancel()
+	if err := g.Manager.RequestResetLocalWorker(ctx, id); err != nil && ctx.Err() == context.DeadlineExceeded {
+		writeError(w, http.StatusGatewayTimeout, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /api/v1/workers/{id}/discover
+func (g *gateway) handleDiscoverWorker(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, _, _, found := g.Manager.GetLocalWorkerInfo(id); !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("local worker '%s' not found", id))
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), discoverTimeout)
+	defer cancel()
+	result, err := g.Manager.Discover(ctx, id)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		writeError(w, http.StatusGatewayTimeout, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeMessage(w, http.StatusOK, result)
+}
+
+// GET /api/v1/workers/{id}/history
+func (g *gateway) handleGetWorkerHistory(w http.ResponseWriter, r *http.Request) {
+	result, err := g.Manager.GetLocalWorkerConfigHistory(r.PathValue("id"))
+	if err != nil {
+		writeError(w, configHistoryStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// rollbackRequest is the body of a rollback request.
+type rollbackRequest struct {
+	// Revision to roll back to (empty to follow the latest configuration again)
+	Revision string `json:"revision"`
+}
+
+// POST /api/v1/workers/{id}/rollback (body: rollbackRequest)
+func (g *gateway) handleRollbackWorker(w http.ResponseWriter, r *http.Request) {
+	var req rollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if err := g.Manager.RollbackLocalWorkerConfig(r.Context(), r.PathValue("id"), req.Revision); err != nil {
+		writeError(w, configHistoryStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// configHistoryStatus returns the HTTP status for an error of the configuration history.
+func configHistoryStatus(err error) int {
+	switch {
+	case errors.Is(err, manager.ErrNoConfigHistory):
+		return http.StatusNotImplemented
+	case errors.Is(err, config.ErrUnknownRevision):
+		return http.StatusBadRequest
+	case errors.Is(err, fs.ErrNotExist):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GET /api/v1/power
+func (g *gateway) handleGetPower(w http.ResponseWriter, r *http.Request) {
+	result := g.Manager.GetPower()
+	writeMessage(w, http.StatusOK, &result)
+}
+
+// PUT /api/v1/power (body: PowerState)
+func (g *gateway) handleSetPower(w http.ResponseWriter, r *http.Request) {
+	var req api.PowerState
+	if !readMessage(w, r, &req) {
+		return
+	}
+	g.Manager.SetPowerRequest(req)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/v1/locs
+func (g *gateway) handleGetLocs(w http.ResponseWriter, r *http.Request) {
+	list := g.Manager.GetAllLocs()
+	sort.Slice(list, func(i, j int) bool { return list[i].GetAddress() < list[j].GetAddress() })
+	writeMessages(w, list)
+}
+
+// GET /api/v1/locs/{module}/{local}
+func (g *gateway) handleGetLoc(w http.ResponseWriter, r *http.Request) {
+	addr := pathAddress(r)
+	result, found := g.Manager.GetLoc(addr)
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("loc '%s' not found", addr))
+		return
+	}
+	writeMessage(w, http.StatusOK, &result)
+}
+
+// PUT /api/v1/locs/{module}/{local} (body: LocState)
+func (g *gateway) handleSetLoc(w http.ResponseWriter, r *http.Request) {
+	var req api.LocState
+	if !readMessage(w, r, &req) {
+		return
+	}
+	g.Manager.SetLocRequest(api.Loc{
+		Address: pathAddress(r),
+		Request: &req,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/v1/switches
+func (g *gateway) handleGetSwitches(w http.ResponseWriter, r *http.Request) {
+	list := g.Manager.GetAllSwitches()
+	sort.Slice(list, func(i, j int) bool { return list[i].GetAddress() < list[j].GetAddress() })
+	writeMessages(w, list)
+}
+
+// GET /api/v1/switches/{module}/{local}
+func (g *gateway) handleGetSwitch(w http.ResponseWriter, r *http.Request) {
+	addr := pathAddress(r)
+	result, found := g.Manager.GetSwitch(addr)
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("switch '%s' not found", addr))
+		return
+	}
+	writeMessage(w, http.StatusOK, &result)
+}
+
+// PUT /api/v1/switches/{module}/{local} (body: SwitchState)
+func (g *gateway) handleSetSwitch(w http.ResponseWriter, r *http.Request) {
+	var req api.SwitchState
+	if !readMessage(w, r, &req) {
+		return
+	}
+	g.Manager.SetSwitchRequest(api.Switch{
+		Address: pathAddress(r),
+		Request: &req,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/v1/outputs
+func (g *gateway) handleGetOutputs(w http.ResponseWriter, r *http.Request) {
+	list := g.Manager.GetAllOutputs()
+	sort.Slice(list, func(i, j int) bool { return list[i].GetAddress() < list[j].GetAddress() })
+	writeMessages(w, list)
+}
+
+// GET /api/v1/outputs/{module}/{local}
+func (g *gateway) handleGetOutput(w http.ResponseWriter, r *http.Request) {
+	addr := pathAddress(r)
+	result, found := g.Manager.GetOutput(addr)
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("output '%s' not found", addr))
+		return
+	}
+	writeMessage(w, http.StatusOK, &result)
+}
+
+// PUT /api/v1/outputs/{module}/{local} (body: OutputState)
+func (g *gateway) handleSetOutput(w http.ResponseWriter, r *http.Request) {
+	var req api.OutputState
+	if !readMessage(w, r, &req) {
+		return
+	}
+	g.Manager.SetOutputRequest(api.Output{
+		Address: pathAddress(r),
+		Request: &req,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /api/v1/sensors
+func (g *gateway) handleGetSensors(w http.ResponseWriter, r *http.Request) {
+	list := g.Manager.GetAllSensors()
+	sort.Slice(list, func(i, j int) bool { return list[i].GetAddress() < list[j].GetAddress() })
+	writeMessages(w, list)
+}
+
+// GET /api/v1/sensors/{module}/{local}
+func (g *gateway) handleGetSensor(w http.ResponseWriter, r *http.Request) {
+	addr := pathAddress(r)
+	result, found := g.Manager.GetSensor(addr)
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Errorf("sensor '%s' not found", addr))
+		return
+	}
+	writeMessage(w, http.StatusOK, &result)
+}
+
+// GET /api/v1/clock
+func (g *gateway) handleGetClock(w http.ResponseWriter, r *http.Request) {
+	result := g.Manager.GetClock()
+	writeMessage(w, http.StatusOK, &result)
+}
+
+// divergentResponse is the response of a single object of which the actual
+// state differs from the requested state.
+type divergentResponse struct {
+	// Domain of the object (loc, output or switch)
+	Domain        string            `json:"domain"`
+	Address       api.ObjectAddress `json:"address"`
+	Since         time.Time         `json:"since"`
+	Attempts      int               `json:"attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+}
+
+// GET /api/v1/divergent
+func (g *gateway) handleGetDivergent(w http.ResponseWriter, r *http.Request) {
+	list := g.Manager.GetDivergentObjects()
+	result := make([]divergentResponse, 0, len(list))
+	for _, x := range list {
+		result = append(result, divergentResponse{
+			Domain:        string(x.Domain),
+			Address:       x.Address,
+			Since:         x.Since,
+			Attempts:      x.Attempts,
+			NextAttemptAt: x.NextAttemptAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// pathAddress returns the object address given in the path of the request.
+func pathAddress(r *http.Request) api.ObjectAddress {
+	return api.JoinModuleLocal(r.PathValue("module"), r.PathValue("local"))
+}
+
+// readMessage decodes the body of the request into the given message.
+// Responds with an error & returns false if the body is invalid.
+func readMessage(w http.ResponseWriter, r *http.Request, msg proto.Message) bool {
+	if err := unmarshaler.Unmarshal(r.Body, msg); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// writeMessage responds with the given message encoded as JSON.
+func writeMessage(w http.ResponseWriter, status int, msg proto.Message) {
+	var buf bytes.Buffer
+	if err := marshaler.Marshal(&buf, msg); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// writeMessages responds with the given messages encoded as a JSON array.
+func writeMessages[T any, PT interface {
+	*T
+	proto.Message
+}](w http.ResponseWriter, list []T) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := range list {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := marshaler.Marshal(&buf, PT(&list[i])); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	buf.WriteByte(']')
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// writeJSON responds with the given value encoded as JSON.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(encoded)
+}
+
+// writeError responds with the given error.
+func writeError(w http.ResponseWriter, status int, err error) {
+	encoded, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(encoded)
+}
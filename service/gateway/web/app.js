@@ -0,0 +1,172 @@
+// Dashboard of the BinkyNet Network Manager.
+// Uses the REST/JSON & WebSocket API of the gateway.
+"use strict";
+
+const api = "/api/v1";
+
+// State per domain, keyed by address
+const switches = new Map();
+const outputs = new Map();
+const sensors = new Map();
+
+function setStatus(text) {
+  document.getElementById("status").textContent = text;
+}
+
+async function request(method, path, body) {
+  const resp = await fetch(api + path, {
+    method: method,
+    // The gateway requires JSON for all requests that change state
+    headers: { "Content-Type": "application/json" },
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  if (!resp.ok) {
+    let msg = resp.statusText;
+    try {
+      msg = (await resp.json()).error || msg;
+    } catch (e) {
+      // Keep status text
+    }
+    throw new Error(msg);
+  }
+  return resp.status === 204 ? null : resp.json();
+}
+
+// run calls the given function and reports failures in the status line.
+async function run(description, fn) {
+  try {
+    await fn();
+    setStatus(description + " done");
+  } catch (e) {
+    setStatus(description + " failed: " + e.message);
+  }
+}
+
+// watch opens a websocket for the given domain and calls onEvent for
+// every received event. The websocket is reopened when it closes.
+function watch(domain, onEvent) {
+  const proto = location.protocol === "https:" ? "wss:" : "ws:";
+  const ws = new WebSocket(proto + "//" + location.host + api + "/watch/" + domain);
+  ws.onmessage = (ev) => onEvent(JSON.parse(ev.data));
+  ws.onclose = () => setTimeout(() => watch(domain, onEvent), 2000);
+}
+
+function cell(row, text, className) {
+  const td = row.insertCell();
+  td.textContent = text === undefined || text === null ? "" : text;
+  if (className) {
+    td.className = className;
+  }
+  return td;
+}
+
+function button(td, label, onClick) {
+  const b = document.createElement("button");
+  b.textContent = label;
+  b.onclick = onClick;
+  td.appendChild(b);
+}
+
+function replaceRows(tableId, entries, fillRow) {
+  const tbody = document.querySelector("#" + tableId + " tbody");
+  tbody.replaceChildren();
+  [...entries].sort((a, b) => (a[0] < b[0] ? -1 : 1)).forEach(([key, x]) => fillRow(tbody.insertRow(), x, key));
+}
+
+// Local workers
+
+async function refreshWorkers() {
+  const workers = await request("GET", "/workers");
+  replaceRows("workers", workers.map((w) => [w.info.id, w]), (row, w) => {
+    const hash = w.info.config_hash;
+    if (hash !== w.requested_config_hash) {
+      row.className = "mismatch";
+    }
+    cell(row, w.info.id);
+    cell(row, w.alias);
+    cell(row, w.info.version);
+    cell(row, w.remote_address);
+    cell(row, new Date(w.last_updated_at).toLocaleTimeString());
+    cell(row, w.liveness, "liveness-" + w.liveness);
+    cell(row, w.requested_config_hash, "hash");
+    cell(row, hash, "hash");
+    const actions = cell(row, "");
+    button(actions, "Reset", () => run("Reset " + w.info.id, () => request("POST", "/workers/" + w.info.id + "/reset")));
+    button(actions, "Discover", () => run("Discover " + w.info.id, async () => {
+      const result = await request("POST", "/workers/" + w.info.id + "/discover");
+      const pre = document.getElementById("discover-result");
+      pre.textContent = w.info.id + ": " + JSON.stringify(result, null, 2);
+      pre.hidden = false;
+    }));
+  });
+}
+
+// Power
+
+function showPower(p) {
+  const show = (x) => (x ? (x.enabled ? "on" : "off") : "?");
+  document.getElementById("power-request").textContent = show(p.request);
+  document.getElementById("power-actual").textContent = show(p.actual);
+}
+
+document.getElementById("power-on").onclick = () =>
+  run("Power on", () => request("PUT", "/power", { enabled: true }));
+document.getElementById("power-off").onclick = () =>
+  run("Power off", () => request("PUT", "/power", { enabled: false }));
+
+// Switches, outputs & sensors
+
+function showSwitches() {
+  replaceRows("switches", switches, (row, s) => {
+    cell(row, s.address);
+    cell(row, s.request && s.request.direction);
+    cell(row, s.actual && s.actual.direction);
+    const current = (s.request || s.actual || {}).direction;
+    const next = current === "STRAIGHT" ? "OFF" : "STRAIGHT";
+    button(cell(row, ""), "Toggle", () =>
+      run("Toggle " + s.address, () => request("PUT", "/switches/" + s.address, { direction: next })));
+  });
+}
+
+function showOutputs() {
+  replaceRows("outputs", outputs, (row, o) => {
+    cell(row, o.address);
+    cell(row, o.request && o.request.value);
+    cell(row, o.actual && o.actual.value);
+  });
+}
+
+function showSensors() {
+  replaceRows("sensors", sensors, (row, s) => {
+    cell(row, s.address);
+    cell(row, s.actual && s.actual.value);
+  });
+}
+
+function showClock(c) {
+  const pad = (x) => String(x).padStart(2, "0");
+  document.getElementById("clock").textContent = pad(c.hours) + ":" + pad(c.minutes) + " " + c.period;
+}
+
+async function init() {
+  await run("Loading", async () => {
+    showPower(await request("GET", "/power"));
+    (await request("GET", "/switches")).forEach((s) => switches.set(s.address, s));
+    (await request("GET", "/outputs")).forEach((o) => outputs.set(o.address, o));
+    (await request("GET", "/sensors")).forEach((s) => sensors.set(s.address, s));
+    showSwitches();
+    showOutputs();
+    showSensors();
+    await refreshWorkers();
+  });
+
+  watch("power", showPower);
+  watch("clock", showClock);
+  watch("switches", (s) => { switches.set(s.address, s); showSwitches(); });
+  watch("outputs", (o) => { outputs.set(o.address, o); showOutputs(); });
+  watch("sensors", (s) => { sensors.set(s.address, s); showSensors(); });
+  watch("workers", () => refreshWorkers().catch((e) => setStatus("Loading workers failed: " + e.message)));
+  watch("liveness", () => refreshWorkers().catch((e) => setStatus("Loading workers failed: " + e.message)));
+}
+
+init();
@@ -0,0 +1,185 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/golang/protobuf/proto"
+	"github.com/gorilla/websocket"
+
+	"github.com/binkynet/NetManager/service/control"
+	"github.com/binkynet/NetManager/service/manager"
+)
+
+const (
+	// Policy used to deliver events of subscriptions.
+	// Clients that do not keep up are disconnected; they receive the
+	// current state again when they reconnect.
+	watchPolicy = manager.PolicyDisconnect
+	// Maximum time to write a message to a websocket
+	writeTimeout = time.Second * 10
+	// Interval of ping messages sent to keep websockets alive
+	pingInterval = time.Second * 30
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// GET /api/v1/watch/workers[?module=<id>]
+func (g *gateway) handleWatchWorkers(w http.ResponseWriter, r *http.Request) {
+	watch(g, w, r, "workers", func(filter manager.ModuleFilter) (chan api.LocalWorker, context.CancelFunc) {
+		return g.Manager.SubscribeLocalWorkerActuals(true, watchPolicy, filter)
+	})
+}
+
+// GET /api/v1/watch/liveness[?module=<id>]
+func (g *gateway) handleWatchLiveness(w http.ResponseWriter, r *http.Request) {
+	watchConverted(g, w, r, "liveness", func(filter manager.ModuleFilter) (chan manager.LocalWorkerLivenessInfo, context.CancelFunc) {
+		return g.Manager.SubscribeLocalWorkerLiveness(true, watchPolicy, filter)
+	}, func(x manager.LocalWorkerLivenessInfo) control.WorkerLiveness {
+		return control.WorkerLiveness{
+			Id:                  x.ID,
+			Liveness:            string(x.Liveness),
+			LastUpdatedUnixtime: x.LastUpdatedAt.Unix(),
+		}
+	})
+}
+
+// GET /api/v1/watch/power
+func (g *gateway) handleWatchPower(w http.ResponseWriter, r *http.Request) {
+	watch(g, w, r, "power", func(manager.ModuleFilter) (chan api.Power, context.CancelFunc) {
+		return g.Manager.SubscribePowerActuals(true, watchPolicy)
+	})
+}
+
+// GET /api/v1/watch/locs
+func (g *gateway) handleWatchLocs(w http.ResponseWriter, r *http.Request) {
+	watch(g, w, r, "locs", func(manager.ModuleFilter) (chan api.Loc, context.CancelFunc) {
+		return g.Manager.SubscribeLocActuals(true, watchPolicy)
+	})
+}
+
+// GET /api/v1/watch/switches[?module=<id>]
+func (g *gateway) handleWatchSwitches(w http.ResponseWriter, r *http.Request) {
+	watch(g, w, r, "switches", func(filter manager.ModuleFilter) (chan api.Switch, context.CancelFunc) {
+		return g.Manager.SubscribeSwitchActuals(true, watchPolicy, filter)
+	})
+}
+
+// GET /api/v1/watch/outputs[?module=<id>]
+func (g *gateway) handleWatchOutputs(w http.ResponseWriter, r *http.Request) {
+	watch(g, w, r, "outputs", func(filter manager.ModuleFilter) (chan api.Output, context.CancelFunc) {
+		return g.Manager.SubscribeOutputActuals(true, watchPolicy, filter)
+	})
+}
+
+// GET /api/v1/watch/sensors[?module=<id>]
+func (g *gateway) handleWatchSensors(w http.ResponseWriter, r *http.Request) {
+	watch(g, w, r, "sensors", func(filter manager.ModuleFilter) (chan api.Sensor, context.CancelFunc) {
+		return g.Manager.SubscribeSensorActuals(true, watchPolicy, filter)
+	})
+}
+
+// GET /api/v1/watch/clock
+func (g *gateway) handleWatchClock(w http.ResponseWriter, r *http.Request) {
+	watch(g, w, r, "clock", func(manager.ModuleFilter) (chan api.Clock, context.CancelFunc) {
+		return g.Manager.SubscribeClockActuals(true, watchPolicy)
+	})
+}
+
+// watch upgrades the request to a websocket and sends all events of the
+// subscription created by the given function as JSON text messages,
+// until the client closes the websocket or the gateway is stopped.
+func watch[T any, PT interface {
+	*T
+	proto.Message
+}](g *gateway, w http.ResponseWriter, r *http.Request, domain string, subscribe func(manager.ModuleFilter) (chan T, context.CancelFunc)) {
+	watchConverted[T, T, PT](g, w, r, domain, subscribe, func(x T) T { return x })
+}
+
+// watchConverted is like watch, for subscriptions of events that are not messages.
+// Every event is converted into a message by the given function before it is sent.
+func watchConverted[S any, T any, PT interface {
+	*T
+	proto.Message
+}](g *gateway, w http.ResponseWriter, r *http.Request, domain string, subscribe func(manager.ModuleFilter) (chan S, context.CancelFunc), convert func(S) T) {
+	log := g.Log.With().Str("domain", domain).Str("remote", r.RemoteAddr).Logger()
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has responded with an error already
+		log.Debug().Err(err).Msg("Failed to upgrade to websocket")
+		return
+	}
+	defer conn.Close()
+
+	// Detect closing of the websocket by the client
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ch, unsubscribe := subscribe(manager.ModuleFilter(r.URL.Query().Get("module")))
+	defer unsubscribe()
+	pingTicker := time.NewTicker(pingInterval)
+	defer pingTicker.Stop()
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				// Client does not keep up
+				log.Debug().Msg("Subscription closed, disconnecting")
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow"),
+					time.Now().Add(writeTimeout))
+				return
+			}
+			msg := convert(event)
+			var buf bytes.Buffer
+			if err := marshaler.Marshal(&buf, PT(&msg)); err != nil {
+				log.Warn().Err(err).Msg("Failed to encode event")
+				continue
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if err := conn.WriteMessage(websocket.TextMessage, buf.Bytes()); err != nil {
+				log.Debug().Err(err).Msg("Failed to send event")
+				return
+			}
+		case <-pingTicker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				log.Debug().Err(err).Msg("Failed to send ping")
+				return
+			}
+		case <-ctx.Done():
+			// Websocket closed or gateway stopped
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+				time.Now().Add(writeTimeout))
+			return
+		}
+	}
+}
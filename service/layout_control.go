@@ -0,0 +1,421 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/binkynet/NetManager/service/config"
+	"github.com/binkynet/NetManager/service/control"
+	"github.com/binkynet/NetManager/service/manager"
+)
+
+// layoutControlService implements the LayoutControlService.
+// It shares all methods with the service, except WatchClock, whose stream
+// has a different type than the one of the NetworkControlService.
+type layoutControlService struct {
+	*service
+}
+
+// LayoutControl returns the implementation of the LayoutControlService.
+func (s *service) LayoutControl() control.LayoutControlServiceServer {
+	return layoutControlService{s}
+}
+
+// Set the requested power state
+func (s *service) SetPowerRequest(ctx context.Context, req *api.PowerState) (*api.Empty, error) {
+	powerMetrics.SetRequestTotalCounters.WithLabelValues("power").Inc()
+	s.Manager.SetPowerRequest(*req)
+	return &api.Empty{}, nil
+}
+
+// Get the requested & actual power state
+func (s *service) GetPower(ctx context.Context, req *api.Empty) (*api.Power, error) {
+	result := s.Manager.GetPower()
+	return &result, nil
+}
+
+// Watch power changes
+func (s *service) WatchPower(req *api.WatchOptions, server control.LayoutControlService_WatchPowerServer) error {
+	powerMetrics.WatchTotalCounter.Inc()
+	ctx := server.Context()
+	ach, acancel := s.Manager.SubscribePowerActuals(req.GetWatchActualChanges(), watchPolicy)
+	defer acancel()
+	for {
+		select {
+		case msg := <-ach:
+			if err := server.Send(&msg); err != nil {
+				s.Log.Warn().Err(err).Msg("Send power actual failed")
+				powerMetrics.WatchActualMessagesFailedTotalCounters.WithLabelValues("power").Inc()
+				return err
+			}
+			powerMetrics.WatchActualMessagesTotalCounters.WithLabelValues("power").Inc()
+		case <-ctx.Done():
+			// Context canceled
+			return nil
+		}
+	}
+}
+
+// Set the requested state of a loc
+func (s *service) SetLocRequest(ctx context.Context, req *api.Loc) (*api.Empty, error) {
+	if req.GetAddress() == "" {
+		return nil, status.Error(codes.InvalidArgument, "address is missing")
+	}
+	locMetrics.SetRequestTotalCounters.WithLabelValues(string(req.GetAddress())).Inc()
+	s.Manager.SetLocRequest(*req)
+	return &api.Empty{}, nil
+}
+
+// Get the state of the loc with the address of the given loc
+func (s *service) GetLoc(ctx context.Context, req *api.Loc) (*api.Loc, error) {
+	result, found := s.Manager.GetLoc(req.GetAddress())
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "loc '%s' not found", req.GetAddress())
+	}
+	return &result, nil
+}
+
+// Watch loc changes.
+// Locs are not bound to a module, so the module filter is ignored.
+func (s *service) WatchLocs(req *api.WatchOptions, server control.LayoutControlService_WatchLocsServer) error {
+	locMetrics.WatchTotalCounter.Inc()
+	ctx := server.Context()
+	ach, acancel := s.Manager.SubscribeLocActuals(req.GetWatchActualChanges(), watchPolicy)
+	defer acancel()
+	for {
+		select {
+		case msg := <-ach:
+			if err := server.Send(&msg); err != nil {
+				s.Log.Warn().Err(err).Msg("Send loc actual failed")
+				locMetrics.WatchActualMessagesFailedTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
+				return err
+			}
+			locMetrics.WatchActualMessagesTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
+		case <-ctx.Done():
+			// Context canceled
+			return nil
+		}
+	}
+}
+
+// Set the requested state of a switch
+func (s *service) SetSwitchRequest(ctx context.Context, req *api.Switch) (*api.Empty, error) {
+	if req.GetAddress() == "" {
+		return nil, status.Error(codes.InvalidArgument, "address is missing")
+	}
+	switchMetrics.SetRequestTotalCounters.WithLabelValues(string(req.GetAddress())).Inc()
+	s.Manager.SetSwitchRequest(*req)
+	return &api.Empty{}, nil
+}
+
+// Get the state of the switch with the address of the given switch
+func (s *service) GetSwitch(ctx context.Context, req *api.Switch) (*api.Switch, error) {
+	result, found := s.Manager.GetSwitch(req.GetAddress())
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "switch '%s' not found", req.GetAddress())
+	}
+	return &result, nil
+}
+
+// Watch switch changes
+func (s *service) WatchSwitches(req *api.WatchOptions, server control.LayoutControlService_WatchSwitchesServer) error {
+	switchMetrics.WatchTotalCounter.Inc()
+	ctx := server.Context()
+	ach, acancel := s.Manager.SubscribeSwitchActuals(req.GetWatchActualChanges(), watchPolicy, manager.ModuleFilter(req.GetModuleId()))
+	defer acancel()
+	for {
+		select {
+		case msg := <-ach:
+			if err := server.Send(&msg); err != nil {
+				s.Log.Warn().Err(err).Msg("Send switch actual failed")
+				switchMetrics.WatchActualMessagesFailedTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
+				return err
+			}
+			switchMetrics.WatchActualMessagesTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
+		case <-ctx.Done():
+			// Context canceled
+			return nil
+		}
+	}
+}
+
+// Set the requested state of an output
+func (s *service) SetOutputRequest(ctx context.Context, req *api.Output) (*api.Empty, error) {
+	if req.GetAddress() == "" {
+		return nil, status.Error(codes.InvalidArgument, "address is missing")
+	}
+	outputMetrics.SetRequestTotalCounters.WithLabelValues(string(req.GetAddress())).Inc()
+	s.Manager.SetOutputRequest(*req)
+	return &api.Empty{}, nil
+}
+
+// Get the state of the output with the address of the given output
+func (s *service) GetOutput(ctx context.Context, req *api.Output) (*api.Output, error) {
+	result, found := s.Manager.GetOutput(req.GetAddress())
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "output '%s' not found", req.GetAddress())
+	}
+	return &result, nil
+}
+
+// Watch output changes
+func (s *service) WatchOutputs(req *api.WatchOptions, server control.LayoutControlService_WatchOutputsServer) error {
+	outputMetrics.WatchTotalCounter.Inc()
+	ctx := server.Context()
+	ach, acancel := s.Manager.SubscribeOutputActuals(req.GetWatchActualChanges(), watchPolicy, manager.ModuleFilter(req.GetModuleId()))
+	defer acancel()
+	for {
+		select {
+		case msg := <-ach:
+			if err := server.Send(&msg); err != nil {
+				s.Log.Warn().Err(err).Msg("Send output actual failed")
+				outputMetrics.WatchActualMessagesFailedTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
+				return err
+			}
+			outputMetrics.WatchActualMessagesTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
+		case <-ctx.Done():
+			// Context canceled
+			return nil
+		}
+	}
+}
+
+// Get the state of the sensor with the address of the given sensor
+func (s *service) GetSensor(ctx context.Context, req *api.Sensor) (*api.Sensor, error) {
+	result, found := s.Manager.GetSensor(req.GetAddress())
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "sensor '%s' not found", req.GetAddress())
+	}
+	return &result, nil
+}
+
+// Watch sensor changes
+func (s *service) WatchSensors(req *api.WatchOptions, server control.LayoutControlService_WatchSensorsServer) error {
+	sensorMetrics.WatchTotalCounter.Inc()
+	ctx := server.Context()
+	ach, acancel := s.Manager.SubscribeSensorActuals(req.GetWatchActualChanges(), watchPolicy, manager.ModuleFilter(req.GetModuleId()))
+	defer acancel()
+	for {
+		select {
+		case msg := <-ach:
+			if err := server.Send(&msg); err != nil {
+				s.Log.Warn().Err(err).Msg("Send sensor actual failed")
+				sensorMetrics.WatchActualMessagesFailedTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
+				return err
+			}
+			sensorMetrics.WatchActualMessagesTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
+		case <-ctx.Done():
+			// Context canceled
+			return nil
+		}
+	}
+}
+
+// Get the actual clock state
+func (s *service) GetClock(ctx context.Context, req *api.Empty) (*api.Clock, error) {
+	result := s.Manager.GetClock()
+	return &result, nil
+}
+
+// Watch clock changes
+func (s layoutControlService) WatchClock(req *api.WatchOptions, server control.LayoutControlService_WatchClockServer) error {
+	return s.service.WatchClock(req, server)
+}
+
+// List all known local workers (with requested config & actual info).
+func (s *service) ListWorkers(req *api.Empty, server control.LayoutControlService_ListWorkersServer) error {
+	for _, info := range s.Manager.GetAllLocalWorkers() {
+		if lw, found := s.getWorker(info.GetId()); found {
+			if err := server.Send(&lw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Get the local worker with the ID of the given local worker
+func (s *service) GetWorker(ctx context.Context, req *api.LocalWorker) (*api.LocalWorker, error) {
+	result, found := s.getWorker(req.GetId())
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "local worker '%s' not found", req.GetId())
+	}
+	return &result, nil
+}
+
+// Get the liveness of the local worker with the ID of the given local worker
+func (s *service) GetWorkerLiveness(ctx context.Context, req *api.LocalWorker) (*control.WorkerLiveness, error) {
+	_, _, lastUpdatedAt, found := s.Manager.GetLocalWorkerInfo(req.GetId())
+	liveness, lfound := s.Manager.GetLocalWorkerLiveness(req.GetId())
+	if !found || !lfound {
+		return nil, status.Errorf(codes.NotFound, "local worker '%s' not found", req.GetId())
+	}
+	result := workerLiveness(manager.LocalWorkerLivenessInfo{
+		ID:            req.GetId(),
+		Liveness:      liveness,
+		LastUpdatedAt: lastUpdatedAt,
+	})
+	return &result, nil
+}
+
+// workerLiveness converts the given liveness of a local worker into a message.
+func workerLiveness(x manager.LocalWorkerLivenessInfo) control.WorkerLiveness {
+	return control.WorkerLiveness{
+		Id:                  x.ID,
+		Liveness:            string(x.Liveness),
+		LastUpdatedUnixtime: x.LastUpdatedAt.Unix(),
+	}
+}
+
+// Watch liveness changes of local workers
+func (s *service) WatchWorkerLiveness(req *api.WatchOptions, server control.LayoutControlService_WatchWorkerLivenessServer) error {
+	ctx := server.Context()
+	ch, cancel := s.Manager.SubscribeLocalWorkerLiveness(true, watchPolicy, manager.ModuleFilter(req.GetModuleId()))
+	defer cancel()
+	for {
+		select {
+		case msg := <-ch:
+			result := workerLiveness(msg)
+			if err := server.Send(&result); err != nil {
+				s.Log.Warn().Err(err).Msg("Send local worker liveness failed")
+				return err
+			}
+		case <-ctx.Done():
+			// Context canceled
+			return nil
+		}
+	}
+}
+
+// Request the local worker with the ID of the given local worker to reset itself
+func (s *service) ResetWorker(ctx context.Context, req *api.LocalWorker) (*api.Empty, error) {
+	if _, _, _, found := s.Manager.GetLocalWorkerInfo(req.GetId()); !found {
+		return nil, status.Errorf(codes.NotFound, "local worker '%s' not found", req.GetId())
+	}
+	if err := s.Manager.RequestResetLocalWorker(ctx, req.GetId()); ctx.Err() != nil {
+		return nil, status.FromContextError(ctx.Err()).Err()
+	} else if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &api.Empty{}, nil
+}
+
+// Discover the devices of the local worker with the ID of the given local worker
+func (s *service) DiscoverWorker(ctx context.Context, req *api.LocalWorker) (*api.DiscoverResult, error) {
+	if _, _, _, found := s.Manager.GetLocalWorkerInfo(req.GetId()); !found {
+		return nil, status.Errorf(codes.NotFound, "local worker '%s' not found", req.GetId())
+	}
+	result, err := s.Manager.Discover(ctx, req.GetId())
+	if err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	return result, nil
+}
+
+// Get the revisions of the configuration of the local worker with the ID of the given local worker
+func (s *service) GetWorkerConfigHistory(ctx context.Context, req *api.LocalWorker) (*control.WorkerConfigHistory, error) {
+	revisions, err := s.Manager.GetLocalWorkerConfigHistory(req.GetId())
+	if err != nil {
+		return nil, configHistoryError(err)
+	}
+	result := &control.WorkerConfigHistory{
+		Id:        req.GetId(),
+		Revisions: make([]*control.WorkerConfigRevision, 0, len(revisions)),
+	}
+	for _, rev := range revisions {
+		result.Revisions = append(result.Revisions, &control.WorkerConfigRevision{
+			Hash:     rev.Hash,
+			Unixtime: rev.Time.Unix(),
+			Author:   rev.Author,
+			Subject:  rev.Subject,
+			Active:   rev.Active,
+		})
+	}
+	return result, nil
+}
+
+// Configure a local worker with its configuration at an earlier revision
+func (s *service) RollbackWorkerConfig(ctx context.Context, req *control.RollbackWorkerConfigRequest) (*api.Empty, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is missing")
+	}
+	if err := s.Manager.RollbackLocalWorkerConfig(ctx, req.GetId(), req.GetRevision()); err != nil {
+		return nil, configHistoryError(err)
+	}
+	return &api.Empty{}, nil
+}
+
+// configHistoryError converts an error of the configuration history into a GRPC status error.
+func configHistoryError(err error) error {
+	switch {
+	case errors.Is(err, manager.ErrNoConfigHistory):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, config.ErrUnknownRevision):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, fs.ErrNotExist):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// Force all local workers to reconfigure, even when their configuration has not changed
+func (s *service) ReconfigureAllWorkers(ctx context.Context, req *api.Empty) (*api.Empty, error) {
+	if err := s.Manager.ReconfigureAllLocalWorkers(ctx); errors.Is(err, manager.ErrNoStateStore) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	} else if err != nil {
+		return nil, err
+	}
+	return &api.Empty{}, nil
+}
+
+// List all objects of which the actual state differs from the requested state
+func (s *service) ListDivergentObjects(req *api.Empty, server control.LayoutControlService_ListDivergentObjectsServer) error {
+	for _, x := range s.Manager.GetDivergentObjects() {
+		if err := server.Send(&control.DivergentObject{
+			Domain:              string(x.Domain),
+			Address:             string(x.Address),
+			SinceUnixtime:       x.Since.Unix(),
+			Attempts:            int32(x.Attempts),
+			NextAttemptUnixtime: x.NextAttemptAt.Unix(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getWorker returns the requested config & actual info of the local worker with given ID.
+func (s *service) getWorker(id string) (api.LocalWorker, bool) {
+	info, _, _, found := s.Manager.GetLocalWorkerInfo(id)
+	if !found {
+		return api.LocalWorker{}, false
+	}
+	result := api.LocalWorker{
+		Id:     id,
+		Actual: &info,
+	}
+	if conf, found := s.Manager.GetLocalWorkerConfig(id); found {
+		result.Request = &conf
+	}
+	return result, true
+}
@@ -0,0 +1,270 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// SubscriberPolicy determines what happens to published values when
+// a subscriber does not keep up with them.
+type SubscriberPolicy int
+
+const (
+	// PolicyDropOldest drops the oldest buffered value when the buffer
+	// of a subscriber is full.
+	PolicyDropOldest SubscriberPolicy = iota
+	// PolicyCoalesce replaces a buffered value by a newer value with the
+	// same key (address or ID) and drops the oldest buffered value when
+	// the buffer of a subscriber is full.
+	PolicyCoalesce
+	// PolicyDisconnect closes the channel of a subscriber when its buffer is full.
+	PolicyDisconnect
+)
+
+const (
+	// Maximum number of values buffered per subscriber
+	subscriberBufferSize = 1024
+)
+
+// String returns a human readable name of the policy.
+func (p SubscriberPolicy) String() string {
+	switch p {
+	case PolicyDropOldest:
+		return "drop-oldest"
+	case PolicyCoalesce:
+		return "coalesce"
+	case PolicyDisconnect:
+		return "disconnect"
+	default:
+		return "unknown"
+	}
+}
+
+// broadcasterOptions describes how a broadcaster handles values of type T.
+type broadcasterOptions[T any] struct {
+	// Name of the broadcaster (used in logs)
+	name string
+	// Returns the key (address or ID) of the given value, used to coalesce values.
+	key func(x T) string
+	// Called when a value has been delivered to a subscriber (optional).
+	onDelivered func(x T)
+	// Called when a value has been dropped for a subscriber (optional).
+	onDropped func(x T)
+}
+
+// broadcaster delivers published values to all of its subscribers.
+// Every subscriber has its own bounded buffer, so publishing never blocks.
+type broadcaster[T any] struct {
+	log     zerolog.Logger
+	options broadcasterOptions[T]
+
+	mutex       sync.Mutex
+	subscribers map[*subscriber[T]]struct{}
+}
+
+// subscriber receives values published by a broadcaster on its channel.
+type subscriber[T any] struct {
+	b       *broadcaster[T]
+	policy  SubscriberPolicy
+	filter  func(x T) bool
+	prepare func(x T) T
+	out     chan T
+	signal  chan struct{}
+	stop    chan struct{}
+	once    sync.Once
+
+	mutex sync.Mutex
+	// Buffered values (PolicyDropOldest & PolicyDisconnect)
+	buffer []T
+	// Keys of buffered values in order of arrival & latest value per key (PolicyCoalesce)
+	keys   []string
+	latest map[string]T
+}
+
+// newBroadcaster creates a new broadcaster using the given options.
+func newBroadcaster[T any](log zerolog.Logger, options broadcasterOptions[T]) *broadcaster[T] {
+	return &broadcaster[T]{
+		log:         log.With().Str("broadcaster", options.name).Logger(),
+		options:     options,
+		subscribers: make(map[*subscriber[T]]struct{}),
+	}
+}
+
+// Publish the given value to all subscribers.
+func (b *broadcaster[T]) Publish(x T) {
+	b.mutex.Lock()
+	subscribers := make([]*subscriber[T], 0, len(b.subscribers))
+	for s := range b.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	b.mutex.Unlock()
+
+	for _, s := range subscribers {
+		s.Push(x)
+	}
+}
+
+// Subscribe adds a subscriber with given policy.
+// Only values that match the given filter (if any) are delivered.
+// The given prepare function (if any) is called for every value just before
+// it is delivered.
+func (b *broadcaster[T]) Subscribe(policy SubscriberPolicy, filter func(x T) bool, prepare func(x T) T) *subscriber[T] {
+	s := &subscriber[T]{
+		b:       b,
+		policy:  policy,
+		filter:  filter,
+		prepare: prepare,
+		out:     make(chan T),
+		signal:  make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		latest:  make(map[string]T),
+	}
+	b.mutex.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mutex.Unlock()
+	go s.run()
+	return s
+}
+
+// C returns the channel on which values are delivered.
+// The channel is closed when the subscriber is closed or disconnected.
+func (s *subscriber[T]) C() chan T {
+	return s.out
+}
+
+// Close removes the subscriber from its broadcaster & closes its channel.
+func (s *subscriber[T]) Close() {
+	s.once.Do(func() {
+		s.b.mutex.Lock()
+		delete(s.b.subscribers, s)
+		s.b.mutex.Unlock()
+		close(s.stop)
+	})
+}
+
+// Push adds the given value to the buffer of the subscriber, applying its policy
+// when the buffer is full. Push never blocks.
+func (s *subscriber[T]) Push(x T) {
+	if s.filter != nil && !s.filter(x) {
+		return
+	}
+	var dropped []T
+	disconnect := false
+	s.mutex.Lock()
+	switch s.policy {
+	case PolicyCoalesce:
+		key := s.b.options.key(x)
+		if _, found := s.latest[key]; !found {
+			if len(s.keys) >= subscriberBufferSize {
+				dropped = append(dropped, s.latest[s.keys[0]])
+				delete(s.latest, s.keys[0])
+				s.keys = s.keys[1:]
+			}
+			s.keys = append(s.keys, key)
+		}
+		s.latest[key] = x
+	case PolicyDisconnect:
+		if len(s.buffer) >= subscriberBufferSize {
+			dropped = append(dropped, s.buffer...)
+			dropped = append(dropped, x)
+			s.buffer = nil
+			disconnect = true
+		} else {
+			s.buffer = append(s.buffer, x)
+		}
+	default:
+		if len(s.buffer) >= subscriberBufferSize {
+			dropped = append(dropped, s.buffer[0])
+			s.buffer = s.buffer[1:]
+		}
+		s.buffer = append(s.buffer, x)
+	}
+	s.mutex.Unlock()
+
+	if onDropped := s.b.options.onDropped; onDropped != nil {
+		for _, x := range dropped {
+			onDropped(x)
+		}
+	}
+	if disconnect {
+		s.b.log.Warn().
+			Int("buffer_size", subscriberBufferSize).
+			Msg("Subscriber does not keep up, disconnecting")
+		s.Close()
+		return
+	}
+	// Wake up delivery
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes the oldest buffered value, waiting until there is one.
+// Returns false when the subscriber has been closed.
+func (s *subscriber[T]) pop() (T, bool) {
+	for {
+		s.mutex.Lock()
+		if len(s.keys) > 0 {
+			key := s.keys[0]
+			x := s.latest[key]
+			delete(s.latest, key)
+			s.keys = s.keys[1:]
+			s.mutex.Unlock()
+			return x, true
+		}
+		if len(s.buffer) > 0 {
+			x := s.buffer[0]
+			s.buffer = s.buffer[1:]
+			s.mutex.Unlock()
+			return x, true
+		}
+		s.mutex.Unlock()
+
+		select {
+		case <-s.signal:
+			// Check buffer again
+		case <-s.stop:
+			var zero T
+			return zero, false
+		}
+	}
+}
+
+// run delivers buffered values to the channel of the subscriber until
+// the subscriber is closed.
+func (s *subscriber[T]) run() {
+	defer close(s.out)
+	for {
+		x, ok := s.pop()
+		if !ok {
+			return
+		}
+		if s.prepare != nil {
+			x = s.prepare(x)
+		}
+		select {
+		case s.out <- x:
+			if onDelivered := s.b.options.onDelivered; onDelivered != nil {
+				onDelivered(x)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,374 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// testValue is a value published in broadcaster tests.
+type testValue struct {
+	key     string
+	version int
+}
+
+const (
+	// Key of the value used to hold up delivery
+	blockerKey = "blocker"
+	// Key of the value published last in a test
+	sentinelKey = "sentinel"
+)
+
+// newTestBroadcaster creates a broadcaster of test values that counts dropped values.
+func newTestBroadcaster(dropped *atomic.Int64) *broadcaster[testValue] {
+	return newBroadcaster(zerolog.Nop(), broadcasterOptions[testValue]{
+		name: "test",
+		key:  func(x testValue) string { return x.key },
+		onDropped: func(testValue) {
+			if dropped != nil {
+				dropped.Add(1)
+			}
+		},
+	})
+}
+
+// subscribeBlocked subscribes to the given broadcaster and publishes a blocker value
+// that is held by the delivery goroutine of the subscriber, so all values published
+// afterwards stay in its buffer until the returned release function is called.
+// The release function publishes a sentinel value before releasing the blocker,
+// so it occupies the last position of the buffer.
+func subscribeBlocked(t *testing.T, b *broadcaster[testValue], policy SubscriberPolicy) (*subscriber[testValue], func()) {
+	t.Helper()
+	held := make(chan struct{})
+	gate := make(chan struct{})
+	s := b.Subscribe(policy, nil, func(x testValue) testValue {
+		if x.key == blockerKey {
+			close(held)
+			<-gate
+		}
+		return x
+	})
+	b.Publish(testValue{key: blockerKey})
+	select {
+	case <-held:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for blocker value to be delivered")
+	}
+	return s, func() {
+		b.Publish(testValue{key: sentinelKey})
+		close(gate)
+	}
+}
+
+// drain receives all values from the given subscriber up to the sentinel value,
+// then closes the subscriber and waits for its channel to be closed.
+// The sentinel value is not part of the result.
+func drain(t *testing.T, s *subscriber[testValue]) []testValue {
+	t.Helper()
+	var result []testValue
+	timeout := time.After(time.Second * 10)
+	for {
+		select {
+		case x, ok := <-s.C():
+			if !ok {
+				t.Fatalf("channel closed before sentinel value, got %d values", len(result))
+			}
+			if x.key != sentinelKey {
+				result = append(result, x)
+				continue
+			}
+			s.Close()
+			for x := range s.C() {
+				t.Errorf("unexpected value %v after sentinel value", x)
+			}
+			return result
+		case <-timeout:
+			t.Fatalf("timeout waiting for sentinel value, got %d values", len(result))
+		}
+	}
+}
+
+func TestBroadcasterDropOldest(t *testing.T) {
+	tests := []struct {
+		name string
+		// Number of values published while the subscriber does not read
+		// (the buffer also holds the sentinel value)
+		count int
+		// Expected versions delivered after the blocker value
+		expectedFirst, expectedLast int
+	}{
+		{
+			name:          "fits in buffer",
+			count:         10,
+			expectedFirst: 0,
+			expectedLast:  9,
+		},
+		{
+			name:          "exactly fills buffer",
+			count:         subscriberBufferSize - 1,
+			expectedFirst: 0,
+			expectedLast:  subscriberBufferSize - 2,
+		},
+		{
+			name:          "oldest values dropped",
+			count:         subscriberBufferSize + 100,
+			expectedFirst: 101,
+			expectedLast:  subscriberBufferSize + 99,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var dropped atomic.Int64
+			b := newTestBroadcaster(&dropped)
+			s, release := subscribeBlocked(t, b, PolicyDropOldest)
+			defer s.Close()
+			for i := 0; i < tc.count; i++ {
+				b.Publish(testValue{key: fmt.Sprintf("k%d", i%3), version: i})
+			}
+			release()
+
+			result := drain(t, s)
+			if len(result) == 0 || result[0].key != blockerKey {
+				t.Fatalf("expected blocker value first, got %v", result)
+			}
+			result = result[1:]
+			expectedCount := tc.expectedLast - tc.expectedFirst + 1
+			if len(result) != expectedCount {
+				t.Fatalf("expected %d values, got %d", expectedCount, len(result))
+			}
+			for i, x := range result {
+				if x.version != tc.expectedFirst+i {
+					t.Fatalf("expected version %d at index %d, got %d", tc.expectedFirst+i, i, x.version)
+				}
+			}
+			if got, expected := dropped.Load(), int64(tc.count-expectedCount); got != expected {
+				t.Errorf("expected %d dropped values, got %d", expected, got)
+			}
+		})
+	}
+}
+
+func TestBroadcasterCoalesce(t *testing.T) {
+	tests := []struct {
+		name      string
+		published []testValue
+		expected  []testValue
+	}{
+		{
+			name:      "distinct keys",
+			published: []testValue{{"a", 1}, {"b", 1}, {"c", 1}},
+			expected:  []testValue{{"a", 1}, {"b", 1}, {"c", 1}},
+		},
+		{
+			name:      "latest value in first arrival position",
+			published: []testValue{{"a", 1}, {"b", 1}, {"c", 1}, {"a", 2}, {"b", 2}, {"a", 3}},
+			expected:  []testValue{{"a", 3}, {"b", 2}, {"c", 1}},
+		},
+		{
+			name:      "single key",
+			published: []testValue{{"a", 1}, {"a", 2}, {"a", 3}},
+			expected:  []testValue{{"a", 3}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestBroadcaster(nil)
+			s, release := subscribeBlocked(t, b, PolicyCoalesce)
+			defer s.Close()
+			for _, x := range tc.published {
+				b.Publish(x)
+			}
+			release()
+
+			result := drain(t, s)
+			if len(result) == 0 || result[0].key != blockerKey {
+				t.Fatalf("expected blocker value first, got %v", result)
+			}
+			result = result[1:]
+			if len(result) != len(tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, result)
+			}
+			for i := range result {
+				if result[i] != tc.expected[i] {
+					t.Fatalf("expected %v, got %v", tc.expected, result)
+				}
+			}
+		})
+	}
+}
+
+func TestBroadcasterCoalesceDropsOldestKey(t *testing.T) {
+	var dropped atomic.Int64
+	b := newTestBroadcaster(&dropped)
+	s, release := subscribeBlocked(t, b, PolicyCoalesce)
+	defer s.Close()
+	// Together with the sentinel value, this overflows the buffer by 2 keys
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		b.Publish(testValue{key: fmt.Sprintf("k%d", i), version: i})
+	}
+	release()
+
+	result := drain(t, s)
+	if len(result) == 0 || result[0].key != blockerKey {
+		t.Fatalf("expected blocker value first, got %d values", len(result))
+	}
+	result = result[1:]
+	if len(result) != subscriberBufferSize-1 {
+		t.Fatalf("expected %d values, got %d", subscriberBufferSize-1, len(result))
+	}
+	if result[0].version != 2 {
+		t.Errorf("expected oldest keys to be dropped, first value is %v", result[0])
+	}
+	if got := dropped.Load(); got != 2 {
+		t.Errorf("expected 2 dropped values, got %d", got)
+	}
+}
+
+func TestBroadcasterDisconnect(t *testing.T) {
+	tests := []struct {
+		name string
+		// Number of values published while the subscriber does not read
+		// (the buffer also holds the sentinel value)
+		count int
+		// Set if the subscriber is expected to be disconnected
+		disconnected bool
+	}{
+		{
+			name:  "fits in buffer",
+			count: subscriberBufferSize - 1,
+		},
+		{
+			name:         "buffer overflow",
+			count:        subscriberBufferSize,
+			disconnected: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var dropped atomic.Int64
+			b := newTestBroadcaster(&dropped)
+			s, release := subscribeBlocked(t, b, PolicyDisconnect)
+			defer s.Close()
+			for i := 0; i < tc.count; i++ {
+				b.Publish(testValue{key: "k", version: i})
+			}
+			release()
+
+			if !tc.disconnected {
+				if got := subscriberCount(b); got != 1 {
+					t.Errorf("expected subscriber to stay subscribed, got %d subscribers", got)
+				}
+				result := drain(t, s)
+				if len(result) != tc.count+1 {
+					t.Errorf("expected %d values, got %d", tc.count+1, len(result))
+				}
+				return
+			}
+			// The channel must be closed, at most the blocker value may still be delivered
+			received := 0
+			timeout := time.After(time.Second)
+			for closed := false; !closed; {
+				select {
+				case _, ok := <-s.C():
+					if ok {
+						received++
+					} else {
+						closed = true
+					}
+				case <-timeout:
+					t.Fatal("timeout waiting for channel to be closed")
+				}
+			}
+			if received > 1 {
+				t.Errorf("expected at most the blocker value, got %d values", received)
+			}
+			if got := subscriberCount(b); got != 0 {
+				t.Errorf("expected subscriber to be removed, got %d subscribers", got)
+			}
+			if got, expected := dropped.Load(), int64(tc.count+1); got != expected {
+				t.Errorf("expected %d dropped values, got %d", expected, got)
+			}
+		})
+	}
+}
+
+func TestBroadcasterPublishDoesNotBlock(t *testing.T) {
+	for _, policy := range []SubscriberPolicy{PolicyDropOldest, PolicyCoalesce, PolicyDisconnect} {
+		t.Run(policy.String(), func(t *testing.T) {
+			b := newTestBroadcaster(nil)
+			// Never read from this subscriber
+			s := b.Subscribe(policy, nil, nil)
+			defer s.Close()
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < subscriberBufferSize*10; i++ {
+					b.Publish(testValue{key: fmt.Sprintf("k%d", i), version: i})
+				}
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second * 10):
+				t.Fatal("Publish blocked on a subscriber that does not read")
+			}
+		})
+	}
+}
+
+func TestBroadcasterCloseIsIdempotent(t *testing.T) {
+	b := newTestBroadcaster(nil)
+	s := b.Subscribe(PolicyDropOldest, nil, nil)
+	other := b.Subscribe(PolicyDropOldest, nil, nil)
+	defer other.Close()
+
+	s.Close()
+	s.Close()
+	select {
+	case _, ok := <-s.C():
+		if ok {
+			t.Error("expected no value after Close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for channel to be closed")
+	}
+	if got := subscriberCount(b); got != 1 {
+		t.Errorf("expected 1 subscriber, got %d", got)
+	}
+
+	// Publishing after Close must only reach the other subscriber
+	b.Publish(testValue{key: "a", version: 1})
+	select {
+	case x := <-other.C():
+		if x.key != "a" {
+			t.Errorf("expected value a, got %v", x)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for value")
+	}
+	s.Close()
+}
+
+// subscriberCount returns the number of subscribers of the given broadcaster.
+func subscriberCount(b *broadcaster[testValue]) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.subscribers)
+}
@@ -20,7 +20,6 @@ import (
 	"time"
 
 	api "github.com/binkynet/BinkyNet/apis/v1"
-	"github.com/mattn/go-pubsub"
 	"github.com/rs/zerolog"
 )
 
@@ -28,13 +27,23 @@ type clockPool struct {
 	mutex         sync.RWMutex
 	log           zerolog.Logger
 	clock         api.Clock
-	actualChanges *pubsub.PubSub
+	actualChanges *broadcaster[api.Clock]
 }
 
 func newClockPool(log zerolog.Logger) *clockPool {
+	log = log.With().Str("pool", "clock").Logger()
 	return &clockPool{
-		log:           log.With().Str("pool", "clock").Logger(),
-		actualChanges: pubsub.New(),
+		log: log,
+		actualChanges: newBroadcaster(log, broadcasterOptions[api.Clock]{
+			name: "clock-actuals",
+			key:  func(api.Clock) string { return "clock" },
+			onDelivered: func(api.Clock) {
+				clockPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues("clock").Inc()
+			},
+			onDropped: func(api.Clock) {
+				clockPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues("clock").Inc()
+			},
+		}),
 	}
 }
 
@@ -45,35 +54,28 @@ func (p *clockPool) SetActual(x api.Clock) {
 	p.clock.Period = x.GetPeriod()
 	p.clock.Hours = x.GetHours()
 	p.clock.Minutes = x.GetMinutes()
-	safePub(p.log, p.actualChanges, p.clock.Clone())
+	p.actualChanges.Publish(*p.clock.Clone())
 	clockPoolMetrics.SetActualTotalCounters.WithLabelValues("clock").Inc()
 }
 
-func (p *clockPool) SubActual(enabled bool, timeout time.Duration) (chan api.Clock, context.CancelFunc) {
+// Get returns the actual clock state.
+func (p *clockPool) Get() api.Clock {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	x := p.clock.Clone()
+	x.Unixtime = time.Now().Unix()
+	return *x
+}
+
+func (p *clockPool) SubActual(enabled bool, policy SubscriberPolicy) (chan api.Clock, context.CancelFunc) {
 	clockPoolMetrics.SubActualTotalCounter.Inc()
-	c := make(chan api.Clock)
-	if enabled {
-		cb := func(msg *api.Clock) {
-			msg.Unixtime = time.Now().Unix()
-			select {
-			case c <- *msg:
-				// Done
-				clockPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues("clock").Inc()
-			case <-time.After(timeout):
-				p.log.Error().
-					Dur("timeout", timeout).
-					Msg("Failed to deliver clock actual to channel")
-				clockPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues("clock").Inc()
-			}
-		}
-		p.actualChanges.Sub(cb)
-		return c, func() {
-			p.actualChanges.Leave(cb)
-			close(c)
-		}
-	} else {
-		return c, func() {
-			close(c)
-		}
+	if !enabled {
+		return disabledSubscription[api.Clock]()
 	}
+	s := p.actualChanges.Subscribe(policy, nil, func(x api.Clock) api.Clock {
+		x.Unixtime = time.Now().Unix()
+		return x
+	})
+	return s.C(), s.Close
 }
@@ -18,10 +18,8 @@ import (
 	"context"
 	"math/rand"
 	"sync"
-	"time"
 
 	api "github.com/binkynet/BinkyNet/apis/v1"
-	"github.com/mattn/go-pubsub"
 	"github.com/rs/zerolog"
 )
 
@@ -29,28 +27,42 @@ type discoverPool struct {
 	log       zerolog.Logger
 	mutex     sync.RWMutex
 	clock     api.Clock
-	requests  *pubsub.PubSub
-	responses *pubsub.PubSub
+	requests  *broadcaster[api.DeviceDiscovery]
+	responses *broadcaster[api.DeviceDiscovery]
 }
 
 func newDiscoverPool(log zerolog.Logger) *discoverPool {
+	log = log.With().Str("pool", "discovery").Logger()
+	key := func(x api.DeviceDiscovery) string { return x.GetId() }
 	return &discoverPool{
-		log:       log.With().Str("pool", "discovery").Logger(),
-		requests:  pubsub.New(),
-		responses: pubsub.New(),
+		log: log,
+		requests: newBroadcaster(log, broadcasterOptions[api.DeviceDiscovery]{
+			name: "discover-requests",
+			key:  key,
+			onDelivered: func(x api.DeviceDiscovery) {
+				discoverPoolMetrics.SubRequestMessagesTotalCounters.WithLabelValues(x.GetId()).Inc()
+			},
+			onDropped: func(x api.DeviceDiscovery) {
+				discoverPoolMetrics.SubRequestMessagesFailedTotalCounters.WithLabelValues(x.GetId()).Inc()
+			},
+		}),
+		responses: newBroadcaster(log, broadcasterOptions[api.DeviceDiscovery]{
+			name: "discover-responses",
+			key:  key,
+			onDelivered: func(x api.DeviceDiscovery) {
+				discoverPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues(x.GetId()).Inc()
+			},
+			onDropped: func(x api.DeviceDiscovery) {
+				discoverPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues(x.GetId()).Inc()
+			},
+		}),
 	}
 }
 
-type discoverRequest struct {
-	id        string
-	requestID int32
-}
-
 // Trigger a discovery and wait for the response.
 func (p *discoverPool) Trigger(ctx context.Context, id string) (*api.DiscoverResult, error) {
 	// Subscribe to results
-	timeout := getTimeout(ctx, time.Minute)
-	resultChan, cancel := p.SubActuals(true, timeout, id)
+	resultChan, cancel := p.SubActuals(true, PolicyDropOldest, id)
 	defer cancel()
 
 	// Trigger discover
@@ -62,7 +74,6 @@ func (p *discoverPool) Trigger(ctx context.Context, id string) (*api.DiscoverRes
 			RequestId: requestID,
 		},
 	})
-	p.requests.Pub(&discoverRequest{id: id})
 
 	// Wait for response
 	for {
@@ -80,78 +91,38 @@ func (p *discoverPool) Trigger(ctx context.Context, id string) (*api.DiscoverRes
 }
 
 // SubRequests is called by the LocalWorker GRPC API to wait for discover requests.
-func (p *discoverPool) SubRequests(enabled bool, timeout time.Duration, id string) (chan api.DeviceDiscovery, context.CancelFunc) {
+func (p *discoverPool) SubRequests(enabled bool, policy SubscriberPolicy, id string) (chan api.DeviceDiscovery, context.CancelFunc) {
 	discoverPoolMetrics.SubRequestTotalCounter.Inc()
-	c := make(chan api.DeviceDiscovery)
-	if enabled {
-		cb := func(msg api.DeviceDiscovery) {
-			if id == "" || msg.GetId() == id {
-				select {
-				case c <- msg:
-					// Done
-					discoverPoolMetrics.SubRequestMessagesTotalCounters.WithLabelValues(id).Inc()
-				case <-time.After(timeout):
-					p.log.Error().
-						Dur("timeout", timeout).
-						Msg("Failed to deliver DeviceDiscovery request to channel")
-					discoverPoolMetrics.SubRequestMessagesFailedTotalCounters.WithLabelValues(id).Inc()
-				}
-			} else {
-				p.log.Debug().Str("msg_id", msg.GetId()).Msg("Skipping message for other localworker")
-			}
-		}
-		p.requests.Sub(cb)
-		return c, func() {
-			p.requests.Leave(cb)
-			close(c)
-		}
-	} else {
-		return c, func() {
-			close(c)
-		}
+	if !enabled {
+		return disabledSubscription[api.DeviceDiscovery]()
 	}
+	s := p.requests.Subscribe(policy, func(msg api.DeviceDiscovery) bool {
+		return id == "" || msg.GetId() == id
+	}, nil)
+	return s.C(), s.Close
 }
 
 // subResponse is called by Trigger.
-func (p *discoverPool) SubActuals(enabled bool, timeout time.Duration, id string) (chan api.DeviceDiscovery, context.CancelFunc) {
+func (p *discoverPool) SubActuals(enabled bool, policy SubscriberPolicy, id string) (chan api.DeviceDiscovery, context.CancelFunc) {
 	discoverPoolMetrics.SubActualTotalCounter.Inc()
-	c := make(chan api.DeviceDiscovery)
-	if enabled {
-		cb := func(msg api.DeviceDiscovery) {
-			if id == "" || msg.GetId() == id {
-				select {
-				case c <- msg:
-					// Done
-					discoverPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues(id).Inc()
-				case <-time.After(timeout):
-					p.log.Error().
-						Dur("timeout", timeout).
-						Msg("Failed to deliver DeviceDiscovery actual to channel")
-					discoverPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues(id).Inc()
-				}
-			}
-		}
-		p.responses.Sub(cb)
-		return c, func() {
-			p.responses.Leave(cb)
-			close(c)
-		}
-	} else {
-		return c, func() {
-			close(c)
-		}
+	if !enabled {
+		return disabledSubscription[api.DeviceDiscovery]()
 	}
+	s := p.responses.Subscribe(policy, func(msg api.DeviceDiscovery) bool {
+		return id == "" || msg.GetId() == id
+	}, nil)
+	return s.C(), s.Close
 }
 
 // SetDiscoverRequest triggers a discovery request
 func (p *discoverPool) SetDiscoverRequest(req api.DeviceDiscovery) {
-	safePub(p.log, p.requests, req)
+	p.requests.Publish(req)
 	discoverPoolMetrics.SetRequestTotalCounters.WithLabelValues(req.GetId()).Inc()
 }
 
 // SetDiscoverResult is called by the local worker in response to discover requests.
 func (p *discoverPool) SetDiscoverResult(req api.DeviceDiscovery) error {
-	safePub(p.log, p.responses, req)
+	p.responses.Publish(req)
 	discoverPoolMetrics.SetActualTotalCounters.WithLabelValues(req.GetId()).Inc()
 	return nil
 }
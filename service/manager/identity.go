@@ -0,0 +1,82 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/binkynet/NetManager/service/state"
+)
+
+// The hash of a local worker configuration consists of the identity of the
+// manager, the configuration generation and a hash of the configuration content.
+// The identity is persisted in the state store (or derived from the hostname
+// when there is no state store), so the hash does not change when the manager
+// restarts. The generation is incremented to force all local workers to reconfigure.
+
+const (
+	// Keys in the manager domain of the state store
+	identityKey         = "identity"
+	configGenerationKey = "config-generation"
+)
+
+// loadIdentity returns the identity & configuration generation of the manager.
+// If there is a state store, a new identity is created & persisted when none exists.
+func loadIdentity(store *state.Store) (string, uint64, error) {
+	if store == nil {
+		hostname, _ := os.Hostname()
+		return fmt.Sprintf("%x", sha1.Sum([]byte(hostname)))[:8], 0, nil
+	}
+	identity, err := store.GetBytes(state.DomainManager, identityKey)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(identity) == 0 {
+		rndData := make([]byte, 4)
+		if _, err := rand.Read(rndData); err != nil {
+			return "", 0, fmt.Errorf("failed to create identity: %w", err)
+		}
+		identity = []byte(fmt.Sprintf("%x", rndData))
+		if err := store.PutBytes(state.DomainManager, identityKey, identity); err != nil {
+			return "", 0, err
+		}
+	}
+	var generation uint64
+	if raw, err := store.GetBytes(state.DomainManager, configGenerationKey); err != nil {
+		return "", 0, err
+	} else if len(raw) > 0 {
+		if generation, err = strconv.ParseUint(string(raw), 10, 64); err != nil {
+			return "", 0, fmt.Errorf("invalid configuration generation '%s': %w", raw, err)
+		}
+	}
+	return string(identity), generation, nil
+}
+
+// saveConfigGeneration persists the given configuration generation (if there is a state store).
+func saveConfigGeneration(store *state.Store, generation uint64) error {
+	if store == nil {
+		return nil
+	}
+	return store.PutBytes(state.DomainManager, configGenerationKey, []byte(strconv.FormatUint(generation, 10)))
+}
+
+// configHashPrefix returns the prefix of local worker configuration hashes.
+func configHashPrefix(identity string, generation uint64) string {
+	return fmt.Sprintf("%s-%d-", identity, generation)
+}
@@ -15,100 +15,26 @@
 package manager
 
 import (
-	"context"
-	"sync"
-	"time"
-
 	api "github.com/binkynet/BinkyNet/apis/v1"
-	"github.com/mattn/go-pubsub"
 	"github.com/rs/zerolog"
 )
 
-type locPool struct {
-	mutex         sync.RWMutex
-	log           zerolog.Logger
-	entries       map[api.ObjectAddress]*api.Loc
-	actualChanges *pubsub.PubSub
-}
+type locPool = objectPool[api.Loc, *api.Loc]
 
 func newLocPool(log zerolog.Logger) *locPool {
-	return &locPool{
-		entries:       make(map[api.ObjectAddress]*api.Loc),
-		log:           log.With().Str("pool", "loc").Logger(),
-		actualChanges: pubsub.New(),
-	}
-}
-
-func (p *locPool) SetRequest(x api.Loc) {
-	addr := string(x.GetAddress())
-	locPoolMetrics.SetRequestTotalCounters.WithLabelValues(addr).Inc()
-	locSpeedInSteps.WithLabelValues(addr).Set(float64(x.GetRequest().GetSpeed()))
-	locDirection.WithLabelValues(addr).Set(locDirectionMetricsValue(x.GetRequest().GetDirection()))
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	e, found := p.entries[x.Address]
-	if !found {
-		x.Actual = nil
-		e = x.Clone()
-		p.entries[x.Address] = e
-	} else {
-		e.Request = x.GetRequest().Clone()
-	}
-}
-
-func (p *locPool) SetActual(x api.Loc) {
-	locPoolMetrics.SetActualTotalCounters.WithLabelValues(string(x.GetAddress())).Inc()
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	e, found := p.entries[x.Address]
-	if !found {
-		// Apparently we do not care for this loc
-		return
-	} else {
-		e.Actual = x.GetActual().Clone()
-	}
-	safePub(p.log, p.actualChanges, e.Clone())
-}
-
-func (p *locPool) SubActual(enabled bool, timeout time.Duration) (chan api.Loc, context.CancelFunc) {
-	locPoolMetrics.SubActualTotalCounter.Inc()
-	c := make(chan api.Loc)
-	if enabled {
-		// Subscribe
-		cb := func(msg *api.Loc) {
-			select {
-			case c <- *msg:
-				// Done
-				locPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
-			case <-time.After(timeout):
-				p.log.Error().
-					Dur("timeout", timeout).
-					Str("address", string(msg.GetAddress())).
-					Msg("Failed to deliver loc actual to channel")
-				locPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
-			}
-		}
-		p.actualChanges.Sub(cb)
-		// Publish all known actual states
-		p.mutex.RLock()
-		for _, loc := range p.entries {
-			if loc.GetActual() != nil {
-				go cb(loc.Clone())
-			}
-		}
-		p.mutex.RUnlock()
-		// Return channel & cancel function
-		return c, func() {
-			p.actualChanges.Leave(cb)
-			close(c)
-		}
-	} else {
-		return c, func() {
-			close(c)
-		}
-	}
+	return newObjectPool[api.Loc](log, objectPoolOptions[api.Loc]{
+		name:       "loc",
+		metrics:    locPoolMetrics,
+		hasRequest: func(x *api.Loc) bool { return x.GetRequest() != nil },
+		setRequest: func(target, source *api.Loc) { target.Request = source.GetRequest().Clone() },
+		hasActual:  func(x *api.Loc) bool { return x.GetActual() != nil },
+		setActual:  func(target, source *api.Loc) { target.Actual = source.GetActual().Clone() },
+		onRequest: func(x *api.Loc) {
+			addr := string(x.GetAddress())
+			locSpeedInSteps.WithLabelValues(addr).Set(float64(x.GetRequest().GetSpeed()))
+			locDirection.WithLabelValues(addr).Set(locDirectionMetricsValue(x.GetRequest().GetDirection()))
+		},
+	})
 }
 
 // locDirectionMetricsValue returns the value to show in loc direction metric
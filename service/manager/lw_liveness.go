@@ -0,0 +1,129 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+)
+
+// LocalWorkerLiveness indicates how recently a local worker has reported
+// its actual state.
+type LocalWorkerLiveness string
+
+const (
+	// LocalWorkerOnline is the liveness of local workers that report regularly
+	LocalWorkerOnline LocalWorkerLiveness = "online"
+	// LocalWorkerStale is the liveness of local workers that have not reported
+	// within the stale timeout
+	LocalWorkerStale LocalWorkerLiveness = "stale"
+	// LocalWorkerOffline is the liveness of local workers that have not reported
+	// within the offline timeout. Offline local workers are no longer sent requests.
+	LocalWorkerOffline LocalWorkerLiveness = "offline"
+
+	// DefaultLocalWorkerStaleTimeout is the default time after which a silent
+	// local worker is considered stale
+	DefaultLocalWorkerStaleTimeout = time.Second * 15
+	// DefaultLocalWorkerOfflineTimeout is the default time after which a silent
+	// local worker is considered offline
+	DefaultLocalWorkerOfflineTimeout = time.Minute
+	// DefaultLocalWorkerEvictTimeout is the default time after which a silent
+	// local worker is forgotten
+	DefaultLocalWorkerEvictTimeout = time.Hour
+
+	// Interval between liveness checks
+	livenessCheckInterval = time.Second
+)
+
+var (
+	allLocalWorkerLiveness = []LocalWorkerLiveness{LocalWorkerOnline, LocalWorkerStale, LocalWorkerOffline}
+)
+
+// LocalWorkerLivenessInfo holds the liveness of a local worker.
+type LocalWorkerLivenessInfo struct {
+	// ID of the local worker
+	ID string
+	// Current liveness of the local worker
+	Liveness LocalWorkerLiveness
+	// Time the local worker last reported its actual state
+	LastUpdatedAt time.Time
+}
+
+// getLiveness returns the liveness of a local worker that last reported at the given time.
+func getLiveness(lastUpdatedAt, now time.Time, staleTimeout, offlineTimeout time.Duration) LocalWorkerLiveness {
+	silence := now.Sub(lastUpdatedAt)
+	switch {
+	case silence >= offlineTimeout:
+		return LocalWorkerOffline
+	case silence >= staleTimeout:
+		return LocalWorkerStale
+	default:
+		return LocalWorkerOnline
+	}
+}
+
+// setLivenessGauges updates the liveness gauges of the local worker with given ID.
+func setLivenessGauges(id string, liveness LocalWorkerLiveness) {
+	for _, l := range allLocalWorkerLiveness {
+		value := 0.0
+		if l == liveness {
+			value = 1
+		}
+		lwLivenessGauges.WithLabelValues(id, string(l)).Set(value)
+	}
+}
+
+// deleteLivenessGauges removes the liveness gauges of the local worker with given ID.
+func deleteLivenessGauges(id string) {
+	for _, l := range allLocalWorkerLiveness {
+		lwLivenessGauges.DeleteLabelValues(id, string(l))
+	}
+}
+
+// runLivenessMonitor periodically updates the liveness of all local workers,
+// until the given context is cancelled.
+func (m *manager) runLivenessMonitor(ctx context.Context) {
+	staleTimeout := m.LocalWorkerStaleTimeout
+	if staleTimeout <= 0 {
+		staleTimeout = DefaultLocalWorkerStaleTimeout
+	}
+	offlineTimeout := m.LocalWorkerOfflineTimeout
+	if offlineTimeout <= 0 {
+		offlineTimeout = DefaultLocalWorkerOfflineTimeout
+	}
+	if offlineTimeout < staleTimeout {
+		offlineTimeout = staleTimeout
+	}
+	evictTimeout := m.LocalWorkerEvictTimeout
+	if evictTimeout <= 0 {
+		evictTimeout = DefaultLocalWorkerEvictTimeout
+	}
+	if evictTimeout < offlineTimeout {
+		evictTimeout = offlineTimeout
+	}
+	ticker := time.NewTicker(livenessCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			for _, id := range m.localWorkerPool.CheckLiveness(now, staleTimeout, offlineTimeout, evictTimeout) {
+				// Requests for local workers that are gone are not going to be delivered
+				m.localWorkerQueues.Remove(id)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
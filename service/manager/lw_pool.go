@@ -16,7 +16,7 @@ package manager
 
 import (
 	"context"
-	"crypto/rand"
+	"crypto/tls"
 	"fmt"
 	"sort"
 	"sync"
@@ -24,35 +24,66 @@ import (
 
 	api "github.com/binkynet/BinkyNet/apis/v1"
 	"github.com/binkynet/NetManager/service/util"
-	"github.com/mattn/go-pubsub"
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
 )
 
 type localWorkerPool struct {
 	log        zerolog.Logger
+	tlsConfig  *tls.Config
 	mutex      sync.RWMutex
-	requests   *pubsub.PubSub
-	actuals    *pubsub.PubSub
+	requests   *broadcaster[api.LocalWorker]
+	actuals    *broadcaster[api.LocalWorker]
+	liveness   *broadcaster[LocalWorkerLivenessInfo]
 	workers    map[string]*localWorkerEntry
 	hashPrefix string
+	// Number of subscriptions of local workers to their own requests,
+	// per local worker ID & remote address of the subscriber
+	requestWatchers map[string]map[string]int
 }
 
 type localWorkerEntry struct {
 	remoteAddr string
 	api.LocalWorker
 	lastUpdatedActualAt time.Time
+	liveness            LocalWorkerLiveness
+	conn                *grpc.ClientConn
 	client              api.LocalWorkerServiceClient
 }
 
-func newLocalWorkerPool(log zerolog.Logger) *localWorkerPool {
-	rndData := make([]byte, 4)
-	rand.Read(rndData)
+func newLocalWorkerPool(log zerolog.Logger, tlsConfig *tls.Config, hashPrefix string) *localWorkerPool {
+	key := func(x api.LocalWorker) string { return x.GetId() }
 	return &localWorkerPool{
-		log:        log,
-		requests:   pubsub.New(),
-		actuals:    pubsub.New(),
+		log:       log,
+		tlsConfig: tlsConfig,
+		requests: newBroadcaster(log, broadcasterOptions[api.LocalWorker]{
+			name: "lw-requests",
+			key:  key,
+			onDelivered: func(x api.LocalWorker) {
+				lwPoolMetrics.SubRequestMessagesTotalCounters.WithLabelValues(x.GetId()).Inc()
+			},
+			onDropped: func(x api.LocalWorker) {
+				lwPoolMetrics.SubRequestMessagesFailedTotalCounters.WithLabelValues(x.GetId()).Inc()
+			},
+		}),
+		actuals: newBroadcaster(log, broadcasterOptions[api.LocalWorker]{
+			name: "lw-actuals",
+			key:  key,
+			onDelivered: func(x api.LocalWorker) {
+				lwPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues(x.GetId()).Inc()
+			},
+			onDropped: func(x api.LocalWorker) {
+				lwPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues(x.GetId()).Inc()
+			},
+		}),
+		liveness: newBroadcaster(log, broadcasterOptions[LocalWorkerLivenessInfo]{
+			name: "lw-liveness",
+			key:  func(x LocalWorkerLivenessInfo) string { return x.ID },
+		}),
 		workers:    make(map[string]*localWorkerEntry),
-		hashPrefix: fmt.Sprintf("%x", rndData),
+		hashPrefix: hashPrefix,
+
+		requestWatchers: make(map[string]map[string]int),
 	}
 }
 
@@ -70,6 +101,73 @@ func (p *localWorkerPool) GetInfo(id string) (api.LocalWorkerInfo, string, time.
 	return api.LocalWorkerInfo{}, "", time.Time{}, false
 }
 
+// HasRequest returns true if a requested state is known for the local worker with given ID.
+func (p *localWorkerPool) HasRequest(id string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if lw, found := p.workers[id]; found {
+		return lw.GetRequest() != nil
+	}
+	return false
+}
+
+// GetRequest returns the requested configuration of the local worker with given ID.
+func (p *localWorkerPool) GetRequest(id string) (*api.LocalWorkerConfig, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if lw, found := p.workers[id]; found && lw.GetRequest() != nil {
+		return lw.GetRequest().Clone(), true
+	}
+	return nil, false
+}
+
+// HasRequestWatcher returns true if the local worker with given ID is subscribed
+// to its own request changes, that is when a request watcher has been added for it
+// from the remote address it reports its actual state from.
+// Local workers watch their own requests to receive configuration changes.
+func (p *localWorkerPool) HasRequestWatcher(id string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if lw, found := p.workers[id]; found && lw.remoteAddr != "" {
+		return p.requestWatchers[id][lw.remoteAddr] > 0
+	}
+	return false
+}
+
+// AddRequestWatcher records a subscription of the local worker with given ID
+// to its own request changes, made from given remote address.
+// The returned function removes the watcher again.
+func (p *localWorkerPool) AddRequestWatcher(id, remoteAddr string) context.CancelFunc {
+	p.updateRequestWatcher(id, remoteAddr, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() { p.updateRequestWatcher(id, remoteAddr, -1) })
+	}
+}
+
+// updateRequestWatcher updates the number of request watchers of the local worker
+// with given ID from given remote address.
+func (p *localWorkerPool) updateRequestWatcher(id, remoteAddr string, delta int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	watchers := p.requestWatchers[id]
+	if watchers == nil {
+		watchers = make(map[string]int)
+		p.requestWatchers[id] = watchers
+	}
+	watchers[remoteAddr] += delta
+	if watchers[remoteAddr] <= 0 {
+		delete(watchers, remoteAddr)
+	}
+	if len(watchers) == 0 {
+		delete(p.requestWatchers, id)
+	}
+}
+
 // GetLocalWorkerServiceClient constructs a client to the LocalWorkerService served
 // on the local worker with given ID.
 func (p *localWorkerPool) GetLocalWorkerServiceClient(id string) (api.LocalWorkerServiceClient, error) {
@@ -89,10 +187,12 @@ func (p *localWorkerPool) GetLocalWorkerServiceClient(id string) (api.LocalWorke
 		if port == 0 {
 			return nil, fmt.Errorf("local worker [%s] does not provide local worker service port", id)
 		}
-		conn, err := util.DialConn(lw.remoteAddr, port, secure)
+		// Local workers must present a certificate for their ID
+		conn, err := util.DialConn(lw.remoteAddr, port, secure, p.tlsConfig, id)
 		if err != nil {
 			return nil, fmt.Errorf("failed to dial local worker: %w", err)
 		}
+		lw.conn = conn
 		lw.client = api.NewLocalWorkerServiceClient(conn)
 		return lw.client, nil
 	}
@@ -111,31 +211,97 @@ func (p *localWorkerPool) RequestReset(ctx context.Context, id string) error {
 	return nil
 }
 
-// GetAll fetches the last known info for all local workers.
-func (p *localWorkerPool) GetAll() []api.LocalWorkerInfo {
+// GetLiveness returns the liveness of the local worker with given ID.
+func (p *localWorkerPool) GetLiveness(id string) (LocalWorkerLiveness, bool) {
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
 
-	result := make([]api.LocalWorkerInfo, 0, len(p.workers))
-	for _, entry := range p.workers {
-		if actual := entry.GetActual(); actual != nil {
-			result = append(result, *actual)
+	if lw, found := p.workers[id]; found && lw.GetActual() != nil {
+		return lw.liveness, true
+	}
+	return "", false
+}
+
+// getLivenessInfo returns the liveness of the given entry.
+func (e *localWorkerEntry) getLivenessInfo() LocalWorkerLivenessInfo {
+	return LocalWorkerLivenessInfo{
+		ID:            e.GetId(),
+		Liveness:      e.liveness,
+		LastUpdatedAt: e.lastUpdatedActualAt,
+	}
+}
+
+// closeClient closes the connection to the LocalWorkerService of the given entry (if any).
+func (e *localWorkerEntry) closeClient() {
+	if e.conn != nil {
+		e.conn.Close()
+	}
+	e.conn = nil
+	e.client = nil
+}
+
+// IsOffline returns true if the local worker with given ID is offline.
+func (p *localWorkerPool) IsOffline(id string) bool {
+	liveness, _ := p.GetLiveness(id)
+	return liveness == LocalWorkerOffline
+}
+
+// CheckLiveness updates the liveness of all local workers, based on the time
+// they last reported their actual state.
+// Changes are published to liveness subscribers.
+// Local workers that have not reported within the evict timeout are removed
+// from the pool; they are configured again when they report again.
+// Returns the IDs of the local workers that went offline or were evicted.
+func (p *localWorkerPool) CheckLiveness(now time.Time, staleTimeout, offlineTimeout, evictTimeout time.Duration) []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var gone []string
+	for id, entry := range p.workers {
+		if entry.GetActual() == nil {
+			continue
+		}
+		if now.Sub(entry.lastUpdatedActualAt) >= evictTimeout {
+			p.log.Info().
+				Str("id", id).
+				Time("last_updated_at", entry.lastUpdatedActualAt).
+				Msg("Evicting local worker")
+			entry.closeClient()
+			delete(p.workers, id)
+			deleteLivenessGauges(id)
+			gone = append(gone, id)
+			continue
+		}
+		liveness := getLiveness(entry.lastUpdatedActualAt, now, staleTimeout, offlineTimeout)
+		if liveness == entry.liveness {
+			continue
+		}
+		p.log.Info().
+			Str("id", id).
+			Str("from", string(entry.liveness)).
+			Str("to", string(liveness)).
+			Time("last_updated_at", entry.lastUpdatedActualAt).
+			Msg("Local worker liveness changed")
+		entry.liveness = liveness
+		setLivenessGauges(id, liveness)
+		p.liveness.Publish(entry.getLivenessInfo())
+		if liveness == LocalWorkerOffline {
+			gone = append(gone, id)
 		}
 	}
-	sort.Slice(result, func(i, j int) bool {
-		return result[i].Id < result[j].Id
-	})
-	return result
+	return gone
 }
 
-// GetAllWorkers fetches the last known state for all local workers.
-func (p *localWorkerPool) GetAllWorkers() []api.LocalWorker {
+// GetAll fetches the last known info for all local workers that are not offline.
+func (p *localWorkerPool) GetAll() []api.LocalWorkerInfo {
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
 
-	result := make([]api.LocalWorker, 0, len(p.workers))
+	result := make([]api.LocalWorkerInfo, 0, len(p.workers))
 	for _, entry := range p.workers {
-		result = append(result, entry.LocalWorker)
+		if actual := entry.GetActual(); actual != nil && entry.liveness != LocalWorkerOffline {
+			result = append(result, *actual)
+		}
 	}
 	sort.Slice(result, func(i, j int) bool {
 		return result[i].Id < result[j].Id
@@ -164,12 +330,34 @@ func (p *localWorkerPool) SetRequest(ctx context.Context, lw api.LocalWorker) er
 	// Set hash
 	entry.LocalWorker.Request.Hash = p.hashPrefix + req.Sha1()
 	// Do not change last updated at
-	safePub(p.log, p.requests, entry.LocalWorker)
+	p.requests.Publish(*entry.LocalWorker.Clone())
 	return nil
 }
 
-// SetActual sets the actual state of a local worker
-func (p *localWorkerPool) SetActual(ctx context.Context, lw api.LocalWorker, remoteAddr string) error {
+// SetHashPrefix changes the prefix of the hash of all requests.
+// All local workers with a request are notified of the changed hash.
+// Returns the IDs of all local workers with a request.
+func (p *localWorkerPool) SetHashPrefix(prefix string) []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.hashPrefix = prefix
+	var ids []string
+	for id, entry := range p.workers {
+		if req := entry.LocalWorker.GetRequest(); req != nil {
+			req.Hash = p.hashPrefix + req.Sha1()
+			p.requests.Publish(*entry.LocalWorker.Clone())
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// SetActual sets the actual state of a local worker.
+// Returns true if the local worker has (re)connected, that is when it was not
+// known before or when it has restarted.
+func (p *localWorkerPool) SetActual(ctx context.Context, lw api.LocalWorker, remoteAddr string) (bool, error) {
 	lwPoolMetrics.SetActualTotalCounters.WithLabelValues(lw.GetId()).Inc()
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
@@ -181,94 +369,94 @@ func (p *localWorkerPool) SetActual(ctx context.Context, lw api.LocalWorker, rem
 		entry.LocalWorker.Id = id
 		p.workers[id] = entry
 	}
+	connected := entry.GetActual() == nil || entry.liveness == LocalWorkerOffline ||
+		lw.GetActual().GetUptime() < entry.GetActual().GetUptime()
 	changed := entry.remoteAddr != remoteAddr ||
 		entry.LocalWorker.GetActual().GetLocalWorkerServicePort() != lw.GetActual().GetLocalWorkerServicePort() ||
 		entry.LocalWorker.GetActual().GetLocalWorkerServiceSecure() != lw.GetActual().GetLocalWorkerServiceSecure()
 	entry.remoteAddr = remoteAddr
 	entry.LocalWorker.Actual = lw.GetActual().Clone()
 	entry.lastUpdatedActualAt = time.Now()
+	if entry.liveness != LocalWorkerOnline {
+		entry.liveness = LocalWorkerOnline
+		setLivenessGauges(id, LocalWorkerOnline)
+		p.liveness.Publish(entry.getLivenessInfo())
+	}
 	if changed {
-		entry.client = nil
+		entry.closeClient()
 	}
-	safePub(p.log, p.actuals, entry.LocalWorker)
-	return nil
+	p.actuals.Publish(*entry.LocalWorker.Clone())
+	return connected, nil
 }
 
 // SubRequests is used to subscribe to all request changes of local workers.
-func (p *localWorkerPool) SubRequests(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc) {
+func (p *localWorkerPool) SubRequests(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc) {
 	lwPoolMetrics.SubRequestTotalCounter.Inc()
-	c := make(chan api.LocalWorker)
-	if enabled {
-		// Subscribe
-		cb := func(msg api.LocalWorker) {
-			if msg.Request != nil && filter.MatchesModuleID(msg.GetId()) {
-				msg.Request.Unixtime = time.Now().Unix()
-				select {
-				case c <- msg:
-					// Done
-					lwPoolMetrics.SubRequestMessagesTotalCounters.WithLabelValues(msg.GetId()).Inc()
-				case <-time.After(timeout):
-					p.log.Error().
-						Dur("timeout", timeout).
-						Msg("Failed to deliver local worker request to channel")
-					lwPoolMetrics.SubRequestMessagesFailedTotalCounters.WithLabelValues(msg.GetId()).Inc()
-				}
-			}
-		}
-		p.requests.Sub(cb)
-		// Push all known request states
-		for _, lw := range p.GetAllWorkers() {
-			if lw.GetRequest() != nil && filter.MatchesModuleID(lw.GetId()) {
-				go cb(lw)
-			}
-		}
-		return c, func() {
-			p.requests.Leave(cb)
-			close(c)
-		}
-	} else {
-		return c, func() {
-			close(c)
+	if !enabled {
+		return disabledSubscription[api.LocalWorker]()
+	}
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	s := p.requests.Subscribe(policy, func(msg api.LocalWorker) bool {
+		return msg.GetRequest() != nil && filter.MatchesModuleID(msg.GetId())
+	}, stampUnixtime)
+	// Push all known request states
+	for _, entry := range p.workers {
+		if entry.GetRequest() != nil {
+			s.Push(*entry.LocalWorker.Clone())
 		}
 	}
+	return s.C(), s.Close
 }
 
 // SubActuals is used to subscribe to actual changes of local workers.
-func (p *localWorkerPool) SubActuals(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc) {
+func (p *localWorkerPool) SubActuals(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc) {
 	lwPoolMetrics.SubActualTotalCounter.Inc()
-	c := make(chan api.LocalWorker)
-	if enabled {
-		cb := func(msg api.LocalWorker) {
-			if filter.MatchesModuleID(msg.GetId()) {
-				if msg.Request != nil {
-					msg.Request.Unixtime = time.Now().Unix()
-				}
-				select {
-				case c <- msg:
-					// Done
-					lwPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues(msg.GetId()).Inc()
-				case <-time.After(timeout):
-					p.log.Error().
-						Dur("timeout", timeout).
-						Msg("Failed to deliver local worker actual to channel")
-					lwPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues(msg.GetId()).Inc()
-				}
-			}
-		}
-		p.actuals.Sub(cb)
-		// Push all known actual states
-		for _, lw := range p.GetAllWorkers() {
-			if lw.GetActual() != nil && filter.MatchesModuleID(lw.GetId()) {
-				go cb(lw)
-			}
-		}
-		return c, func() {
-			p.actuals.Leave(cb)
-			close(c)
+	if !enabled {
+		return disabledSubscription[api.LocalWorker]()
+	}
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	s := p.actuals.Subscribe(policy, func(msg api.LocalWorker) bool {
+		return filter.MatchesModuleID(msg.GetId())
+	}, stampUnixtime)
+	// Push all known actual states (of local workers that are not offline)
+	for _, entry := range p.workers {
+		if entry.GetActual() != nil && entry.liveness != LocalWorkerOffline {
+			s.Push(*entry.LocalWorker.Clone())
 		}
-	} else {
-		return c, func() {
-			close(c)
+	}
+	return s.C(), s.Close
+}
+
+// SubLiveness is used to subscribe to liveness changes of local workers.
+func (p *localWorkerPool) SubLiveness(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan LocalWorkerLivenessInfo, context.CancelFunc) {
+	if !enabled {
+		return disabledSubscription[LocalWorkerLivenessInfo]()
+	}
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	s := p.liveness.Subscribe(policy, func(msg LocalWorkerLivenessInfo) bool {
+		return filter.MatchesModuleID(msg.ID)
+	}, nil)
+	// Push the liveness of all known local workers
+	for _, entry := range p.workers {
+		if entry.GetActual() != nil {
+			s.Push(entry.getLivenessInfo())
 		}
 	}
+	return s.C(), s.Close
+}
+
+// stampUnixtime sets the current time in the request of the given local worker
+// (if any), just before it is delivered to a subscriber.
+func stampUnixtime(msg api.LocalWorker) api.LocalWorker {
+	if msg.Request != nil {
+		msg.Request = msg.Request.Clone()
+		msg.Request.Unixtime = time.Now().Unix()
+	}
+	return msg
 }
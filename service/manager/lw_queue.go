@@ -0,0 +1,198 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/rs/zerolog"
+
+	"github.com/binkynet/NetManager/service/state"
+)
+
+const (
+	// DefaultLocalWorkerRequestTimeout is the default maximum time a single
+	// request to a local worker may take
+	DefaultLocalWorkerRequestTimeout = time.Second * 5
+	// DefaultLocalWorkerQueueSize is the default maximum number of requests
+	// waiting to be sent to a single local worker
+	DefaultLocalWorkerQueueSize = 64
+
+	// Domain of device discovery requests in local worker queues.
+	// Discovery requests are never stored in the state store.
+	domainDiscovery state.Domain = "discovery"
+)
+
+// lwRequest is a single request waiting to be sent to a local worker.
+type lwRequest struct {
+	// Domain of the request (used in metrics & logs)
+	domain state.Domain
+	// Key of the object the request is for.
+	// A waiting request is replaced by a newer request with the same domain & key.
+	key string
+	// Time the request was queued
+	queuedAt time.Time
+	// Send the request using the given client
+	send func(ctx context.Context, client api.LocalWorkerServiceClient) error
+}
+
+// lwQueue holds the requests waiting to be sent to a single local worker.
+type lwQueue struct {
+	requests []lwRequest
+	// Set while a goroutine is sending the requests of this queue
+	running bool
+	// Set when the queue is removed while a goroutine is still sending
+	removed bool
+}
+
+// lwQueues sends requests to local workers, one at a time per local worker,
+// in the order they were queued.
+type lwQueues struct {
+	log       zerolog.Logger
+	getClient func(id string) (api.LocalWorkerServiceClient, error)
+	timeout   time.Duration
+	size      int
+
+	mutex  sync.Mutex
+	queues map[string]*lwQueue
+}
+
+// newLocalWorkerQueues creates a new set of local worker queues.
+func newLocalWorkerQueues(log zerolog.Logger, getClient func(id string) (api.LocalWorkerServiceClient, error), timeout time.Duration, size int) *lwQueues {
+	if timeout <= 0 {
+		timeout = DefaultLocalWorkerRequestTimeout
+	}
+	if size <= 0 {
+		size = DefaultLocalWorkerQueueSize
+	}
+	return &lwQueues{
+		log:       log.With().Str("component", "lw-queues").Logger(),
+		getClient: getClient,
+		timeout:   timeout,
+		size:      size,
+		queues:    make(map[string]*lwQueue),
+	}
+}
+
+// Enqueue adds a request for the object with given domain & key to the queue of
+// the local worker with given ID.
+// A request for the same object that is still waiting is replaced (in place).
+// When the queue is full, the oldest waiting request is dropped.
+func (q *lwQueues) Enqueue(id string, domain state.Domain, key string, send func(ctx context.Context, client api.LocalWorkerServiceClient) error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	lwq, found := q.queues[id]
+	if !found {
+		lwq = &lwQueue{}
+		q.queues[id] = lwq
+	}
+	lwq.removed = false
+	request := lwRequest{
+		domain:   domain,
+		key:      key,
+		queuedAt: time.Now(),
+		send:     send,
+	}
+	// Replace superseded request (if any)
+	for i, r := range lwq.requests {
+		if r.domain == domain && r.key == key {
+			lwq.requests[i] = request
+			lwQueueCoalescedTotalCounters.WithLabelValues(id).Inc()
+			return
+		}
+	}
+	// Make room
+	if len(lwq.requests) >= q.size {
+		dropped := lwq.requests[0]
+		lwq.requests = lwq.requests[1:]
+		lwQueueDroppedTotalCounters.WithLabelValues(id).Inc()
+		q.log.Warn().
+			Str("id", id).
+			Str("domain", string(dropped.domain)).
+			Str("key", dropped.key).
+			Msg("Outbound queue of local worker is full, dropping oldest request")
+	}
+	lwq.requests = append(lwq.requests, request)
+	lwQueueDepthGauges.WithLabelValues(id).Set(float64(len(lwq.requests)))
+	if !lwq.running {
+		lwq.running = true
+		go q.run(id, lwq)
+	}
+}
+
+// Remove drops all requests waiting to be sent to the local worker with
+// given ID and forgets its queue.
+// A request that is being sent is not interrupted.
+func (q *lwQueues) Remove(id string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	lwq, found := q.queues[id]
+	if !found {
+		return
+	}
+	lwq.requests = nil
+	if lwq.running {
+		// Let the sending goroutine forget the queue when it is done
+		lwq.removed = true
+	} else {
+		delete(q.queues, id)
+	}
+	lwQueueDepthGauges.DeleteLabelValues(id)
+}
+
+// run sends the requests of the given queue until it is empty.
+func (q *lwQueues) run(id string, lwq *lwQueue) {
+	for {
+		q.mutex.Lock()
+		if len(lwq.requests) == 0 {
+			lwq.running = false
+			if lwq.removed && q.queues[id] == lwq {
+				delete(q.queues, id)
+			}
+			q.mutex.Unlock()
+			return
+		}
+		r := lwq.requests[0]
+		lwq.requests = lwq.requests[1:]
+		lwQueueDepthGauges.WithLabelValues(id).Set(float64(len(lwq.requests)))
+		q.mutex.Unlock()
+
+		if err := q.send(id, r); err != nil {
+			lwRequestFailedTotalCounters.WithLabelValues(id, string(r.domain)).Inc()
+			q.log.Error().Err(err).
+				Str("id", id).
+				Str("domain", string(r.domain)).
+				Str("key", r.key).
+				Msg("Failed to send request to local worker")
+		}
+		lwRequestLatency.WithLabelValues(id, string(r.domain)).Observe(time.Since(r.queuedAt).Seconds())
+	}
+}
+
+// send the given request to the local worker with given ID.
+func (q *lwQueues) send(id string, r lwRequest) error {
+	client, err := q.getClient(id)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+	defer cancel()
+	return r.send(ctx, client)
+}
@@ -0,0 +1,122 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/rs/zerolog"
+
+	"github.com/binkynet/NetManager/service/state"
+)
+
+// queueRecorder records the requests sent by local worker queues.
+type queueRecorder struct {
+	sent chan string
+	gate chan struct{}
+}
+
+// newQueueRecorder creates a recorder & local worker queues that send to it.
+func newQueueRecorder() (*queueRecorder, *lwQueues) {
+	r := &queueRecorder{
+		sent: make(chan string, 16),
+		gate: make(chan struct{}),
+	}
+	getClient := func(id string) (api.LocalWorkerServiceClient, error) { return nil, nil }
+	return r, newLocalWorkerQueues(zerolog.Nop(), getClient, time.Second, 0)
+}
+
+// sender returns a request sender that records the given name.
+func (r *queueRecorder) sender(name string) func(ctx context.Context, client api.LocalWorkerServiceClient) error {
+	return func(ctx context.Context, client api.LocalWorkerServiceClient) error {
+		r.sent <- name
+		return nil
+	}
+}
+
+// blockingSender returns a request sender that records the given name and
+// then waits until the gate is closed.
+func (r *queueRecorder) blockingSender(name string) func(ctx context.Context, client api.LocalWorkerServiceClient) error {
+	return func(ctx context.Context, client api.LocalWorkerServiceClient) error {
+		r.sent <- name
+		<-r.gate
+		return nil
+	}
+}
+
+// receive returns the names of the given number of sent requests.
+func (r *queueRecorder) receive(t *testing.T, count int) []string {
+	t.Helper()
+	var result []string
+	for len(result) < count {
+		select {
+		case name := <-r.sent:
+			result = append(result, name)
+		case <-time.After(time.Second * 10):
+			t.Fatalf("expected %d requests, got %v", count, result)
+		}
+	}
+	return result
+}
+
+func TestLocalWorkerQueueCoalesceInPlace(t *testing.T) {
+	r, q := newQueueRecorder()
+	q.Enqueue("w1", state.DomainSwitch, "blocker", r.blockingSender("blocker"))
+	r.receive(t, 1)
+	q.Enqueue("w1", state.DomainSwitch, "s1", r.sender("s1 first"))
+	q.Enqueue("w1", state.DomainSwitch, "s2", r.sender("s2"))
+	q.Enqueue("w1", state.DomainSwitch, "s1", r.sender("s1 second"))
+	close(r.gate)
+
+	expected := []string{"s1 second", "s2"}
+	if got := r.receive(t, 2); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	expectNothing(t, r.sent)
+}
+
+func TestLocalWorkerQueueRemove(t *testing.T) {
+	r, q := newQueueRecorder()
+	q.Enqueue("w1", state.DomainSwitch, "blocker", r.blockingSender("blocker"))
+	r.receive(t, 1)
+	q.Enqueue("w1", state.DomainSwitch, "s1", r.sender("s1"))
+	q.Remove("w1")
+	close(r.gate)
+	expectNothing(t, r.sent)
+
+	deadline := time.Now().Add(time.Second * 10)
+	for {
+		q.mutex.Lock()
+		_, found := q.queues["w1"]
+		q.mutex.Unlock()
+		if !found {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected queue to be forgotten")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// A new request is sent again
+	q.Enqueue("w1", state.DomainSwitch, "s2", r.sender("s2"))
+	if got := r.receive(t, 1); got[0] != "s2" {
+		t.Errorf("expected s2, got %v", got)
+	}
+}
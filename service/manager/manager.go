@@ -17,15 +17,16 @@ package manager
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
-	"github.com/mattn/go-pubsub"
 	mqtt "github.com/mochi-mqtt/server/v2"
-	"github.com/mochi-mqtt/server/v2/hooks/auth"
-	"github.com/mochi-mqtt/server/v2/listeners"
 	"github.com/rs/zerolog"
 
 	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/binkynet/NetManager/service/config"
+	"github.com/binkynet/NetManager/service/state"
+	"github.com/binkynet/NetManager/service/util"
 )
 
 // Manager is the abstraction of the core of the network manager.
@@ -36,18 +37,41 @@ type Manager interface {
 	// GetLocalWorkerInfo fetches the last known info for a local worker with given ID.
 	// Returns: info, remoteAddr, lastUpdatedAt, found
 	GetLocalWorkerInfo(id string) (api.LocalWorkerInfo, string, time.Time, bool)
-	// GetAllLocalWorkers fetches the last known info for all local workers.
+	// GetAllLocalWorkers fetches the last known info for all local workers that are not offline.
 	GetAllLocalWorkers() []api.LocalWorkerInfo
+	// GetLocalWorkerConfig fetches the configuration requested for a local worker with given ID.
+	GetLocalWorkerConfig(id string) (api.LocalWorkerConfig, bool)
+	// GetLocalWorkerLiveness returns the liveness of the local worker with given ID.
+	GetLocalWorkerLiveness(id string) (LocalWorkerLiveness, bool)
+	// GetDivergentObjects returns all objects of which the actual state differs
+	// from the requested state.
+	GetDivergentObjects() []DivergentObject
 	// SubscribeLocalWorkerRequests is used to subscribe to requested changes of local workers.
-	SubscribeLocalWorkerRequests(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc)
+	SubscribeLocalWorkerRequests(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc)
+	// AddLocalWorkerRequestWatcher records that the local worker with given ID watches
+	// its own requested state from given remote address, so configuration changes reach
+	// it without a reset. Call the returned function when the watch ends.
+	AddLocalWorkerRequestWatcher(id, remoteAddr string) context.CancelFunc
 	// SubscribeLocalWorkerActuals is used to subscribe to actual changes of local workers.
-	SubscribeLocalWorkerActuals(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc)
+	SubscribeLocalWorkerActuals(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc)
+	// SubscribeLocalWorkerLiveness is used to subscribe to liveness changes of local workers.
+	SubscribeLocalWorkerLiveness(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan LocalWorkerLivenessInfo, context.CancelFunc)
 	// SetLocalWorkerRequest sets the requested state of a local worker
 	SetLocalWorkerRequest(ctx context.Context, info api.LocalWorker) error
 	// SetLocalWorkerActual sets the actual state of a local worker
 	SetLocalWorkerActual(ctx context.Context, info api.LocalWorker, remoteAddr string) error
 	// RequestResetLocalWorker requests the local worker with given ID to reset itself.
-	RequestResetLocalWorker(ctx context.Context, id string)
+	// Returns an error when the local worker cannot be reached or refuses the request.
+	RequestResetLocalWorker(ctx context.Context, id string) error
+	// ReconfigureAllLocalWorkers forces all local workers to reconfigure,
+	// even when their configuration has not changed.
+	// Returns ErrNoStateStore when the manager has no state store.
+	ReconfigureAllLocalWorkers(ctx context.Context) error
+	// GetLocalWorkerConfigHistory returns the revisions of the configuration of the local worker with given ID.
+	GetLocalWorkerConfigHistory(id string) ([]config.Revision, error)
+	// RollbackLocalWorkerConfig configures the local worker with given ID with its configuration
+	// at the given revision. If revision is empty, the local worker follows the latest configuration again.
+	RollbackLocalWorkerConfig(ctx context.Context, id, revision string) error
 
 	// Trigger a discovery and wait for the response.
 	Discover(ctx context.Context, id string) (*api.DiscoverResult, error)
@@ -56,45 +80,93 @@ type Manager interface {
 	// SetDevicesDiscoveryActual is called by the local worker in response to discover requests.
 	SetDevicesDiscoveryActual(ctx context.Context, req api.DeviceDiscovery) error
 	// Subscribe to discovery actuals
-	SubscribeDiscoverActuals(enabled bool, timeout time.Duration, id string) (chan api.DeviceDiscovery, context.CancelFunc)
+	SubscribeDiscoverActuals(enabled bool, policy SubscriberPolicy, id string) (chan api.DeviceDiscovery, context.CancelFunc)
 
+	// Get the requested & actual power state
+	GetPower() api.Power
 	// Set the requested power state
 	SetPowerRequest(x api.PowerState)
 	// Set the actual power state
 	SetPowerActual(x api.PowerState)
 	// Subscribe to power actuals
-	SubscribePowerActuals(enabled bool, timeout time.Duration) (chan api.Power, context.CancelFunc)
+	SubscribePowerActuals(enabled bool, policy SubscriberPolicy) (chan api.Power, context.CancelFunc)
 
+	// Get the state of the loc with given address
+	GetLoc(addr api.ObjectAddress) (api.Loc, bool)
+	// Get the state of all known locs
+	GetAllLocs() []api.Loc
 	// Set the requested loc state
 	SetLocRequest(x api.Loc)
 	// Set the actual loc state
 	SetLocActual(x api.Loc)
 	// Subscribe to loc actuals
-	SubscribeLocActuals(enabled bool, timeout time.Duration) (chan api.Loc, context.CancelFunc)
+	SubscribeLocActuals(enabled bool, policy SubscriberPolicy) (chan api.Loc, context.CancelFunc)
 
+	// Get the state of the output with given address
+	GetOutput(addr api.ObjectAddress) (api.Output, bool)
+	// Get the state of all known outputs
+	GetAllOutputs() []api.Output
 	// Set the requested output state
 	SetOutputRequest(x api.Output)
 	// Set the actual output state
 	SetOutputActual(x api.Output)
 	// Subscribe to output actuals
-	SubscribeOutputActuals(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.Output, context.CancelFunc)
+	SubscribeOutputActuals(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.Output, context.CancelFunc)
 
+	// Get the state of the sensor with given address
+	GetSensor(addr api.ObjectAddress) (api.Sensor, bool)
+	// Get the state of all known sensors
+	GetAllSensors() []api.Sensor
 	// Set the actual sensor state
 	SetSensorActual(x api.Sensor)
 	// Subscribe to sensor actuals
-	SubscribeSensorActuals(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.Sensor, context.CancelFunc)
+	SubscribeSensorActuals(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.Sensor, context.CancelFunc)
 
+	// Get the state of the switch with given address
+	GetSwitch(addr api.ObjectAddress) (api.Switch, bool)
+	// Get the state of all known switches
+	GetAllSwitches() []api.Switch
 	// Set the requested switch state
 	SetSwitchRequest(x api.Switch)
 	// Set the actual switch state
 	SetSwitchActual(x api.Switch)
 	// Subscribe to switch actuals
-	SubscribeSwitchActuals(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.Switch, context.CancelFunc)
+	SubscribeSwitchActuals(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.Switch, context.CancelFunc)
 
+	// Get the actual clock state
+	GetClock() api.Clock
 	// Set the actual clock state
 	SetClockActual(x api.Clock)
 	// Subscribe to clock actuals
-	SubscribeClockActuals(enabled bool, timeout time.Duration) (chan api.Clock, context.CancelFunc)
+	SubscribeClockActuals(enabled bool, policy SubscriberPolicy) (chan api.Clock, context.CancelFunc)
+}
+
+// Config of the manager.
+type Config struct {
+	// MQTT holds the configuration of the embedded MQTT server.
+	// Ignored when an MQTT server is passed in the dependencies.
+	MQTT MQTTConfig
+	// LocalWorkerTLS holds the credentials used to dial secure
+	// LocalWorkerService's. Local workers must present a certificate
+	// that is valid for their ID.
+	LocalWorkerTLS util.ClientTLSConfig
+	// Time after which a local worker that has not reported its actual state
+	// is considered stale (defaults to DefaultLocalWorkerStaleTimeout).
+	LocalWorkerStaleTimeout time.Duration
+	// Time after which a local worker that has not reported its actual state
+	// is considered offline (defaults to DefaultLocalWorkerOfflineTimeout).
+	// Offline local workers are no longer sent requests.
+	LocalWorkerOfflineTimeout time.Duration
+	// Time after which a local worker that has not reported its actual state
+	// is forgotten (defaults to DefaultLocalWorkerEvictTimeout).
+	// It is configured again when it reports again.
+	LocalWorkerEvictTimeout time.Duration
+	// Maximum time a single request to a local worker may take
+	// (defaults to DefaultLocalWorkerRequestTimeout).
+	LocalWorkerRequestTimeout time.Duration
+	// Maximum number of requests waiting to be sent to a single local worker
+	// (defaults to DefaultLocalWorkerQueueSize).
+	LocalWorkerQueueSize int
 }
 
 // Dependencies of the manager.
@@ -105,6 +177,14 @@ type Dependencies struct {
 	// If nil, a new one is created.
 	MQTTServer *mqtt.Server
 
+	// ConfigRegistry is used to lookup the configuration of local workers.
+	// If nil, local workers are not configured by the manager.
+	ConfigRegistry config.Registry
+
+	// StateStore is used to persist requested states.
+	// If nil, requested states are kept in memory only.
+	StateStore *state.Store
+
 	// Reconfiguration queue (chan localWorkerID).
 	// The manager must listen to entries in this queue and reconfigure
 	// when it receives a local worker ID.
@@ -112,74 +192,62 @@ type Dependencies struct {
 }
 
 // New creates a new Manager.
-func New(deps Dependencies) (Manager, error) {
+func New(conf Config, deps Dependencies) (Manager, error) {
 	mqttServer := deps.MQTTServer
 	if mqttServer == nil {
+		// The bridge accepts requests from any MQTT client, so it must not be
+		// enabled on a server that allows all clients.
+		if conf.MQTT.Bridge && conf.MQTT.CredentialsFile == "" {
+			return nil, fmt.Errorf("MQTT bridge requires MQTT credentials")
+		}
 		var err error
-		mqttServer, err = NewMQTTServer()
+		mqttServer, err = NewMQTTServer(conf.MQTT, deps.Log)
 		if err != nil {
 			return nil, err
 		}
 	}
-
-	return &manager{
-		Dependencies:    deps,
-		mqttServer:      mqttServer,
-		configChanges:   pubsub.New(),
-		discoverPool:    newDiscoverPool(deps.Log),
-		powerPool:       newPowerPool(deps.Log),
-		locPool:         newLocPool(deps.Log),
-		outputPool:      newOutputPool(deps.Log),
-		sensorPool:      newSensorPool(deps.Log),
-		switchPool:      newSwitchPool(deps.Log),
-		clockPool:       newClockPool(deps.Log),
-		localWorkerPool: newLocalWorkerPool(deps.Log),
-	}, nil
-
-}
-
-// NewMQTTServer creates a new MQTT server with default settings.
-func NewMQTTServer() (*mqtt.Server, error) {
-	options := &mqtt.Options{
-		InlineClient: true,
+	lwTLSConfig, err := util.NewClientTLS(conf.LocalWorkerTLS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare local worker TLS: %w", err)
 	}
-	mqttServer := mqtt.New(options)
-	// For security reasons, the default implementation disallows all connections.
-	// If you want to allow all connections, you must specifically allow it.
-	if err := mqttServer.AddHook(new(auth.AllowHook), nil); err != nil {
-		return nil, fmt.Errorf("MQTT Hook configuration failed: %w", err)
+	identity, generation, err := loadIdentity(deps.StateStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load manager identity: %w", err)
 	}
-	return mqttServer, nil
-}
 
-// RunMQTTServer runs the given MQTT server until the given context is cancelled.
-func RunMQTTServer(ctx context.Context, mqttServer *mqtt.Server, address string, log zerolog.Logger) error {
-	tcp := listeners.NewTCP(listeners.Config{
-		ID:      "t1",
-		Address: address,
-	})
-	if err := mqttServer.AddListener(tcp); err != nil {
-		return fmt.Errorf("MQTT Listener configuration failed: %w", err)
+	m := &manager{
+		Config:             conf,
+		Dependencies:       deps,
+		mqttServer:         mqttServer,
+		discoverPool:       newDiscoverPool(deps.Log),
+		powerPool:          newPowerPool(deps.Log),
+		locPool:            newLocPool(deps.Log),
+		outputPool:         newOutputPool(deps.Log),
+		sensorPool:         newSensorPool(deps.Log),
+		switchPool:         newSwitchPool(deps.Log),
+		clockPool:          newClockPool(deps.Log),
+		localWorkerPool:    newLocalWorkerPool(deps.Log, lwTLSConfig, configHashPrefix(identity, generation)),
+		identity:           identity,
+		generation:         generation,
+		configFailed:       make(map[string]struct{}),
+		pendingState:       make(map[pendingStateKey][]byte),
+		pendingStateSignal: make(chan struct{}, 1),
 	}
-	go func() {
-		if err := mqttServer.Serve(); err != nil {
-			log.Error().Err(err).Msg("MQTT Serve failed")
-		}
-	}()
-	<-ctx.Done()
-	mqttServer.Close()
-	tcp.Close(func(id string) {
-		// Do nothing
-	})
-	return nil
+	m.localWorkerQueues = newLocalWorkerQueues(deps.Log, m.localWorkerPool.GetLocalWorkerServiceClient,
+		conf.LocalWorkerRequestTimeout, conf.LocalWorkerQueueSize)
+	if err := m.restoreState(); err != nil {
+		return nil, fmt.Errorf("failed to restore requested state: %w", err)
+	}
+	return m, nil
+
 }
 
 // manager implements the core of the network manager
 type manager struct {
+	Config
 	Dependencies
 
 	mqttServer      *mqtt.Server
-	configChanges   *pubsub.PubSub
 	discoverPool    *discoverPool
 	powerPool       *powerPool
 	locPool         *locPool
@@ -188,6 +256,25 @@ type manager struct {
 	switchPool      *switchPool
 	clockPool       *clockPool
 	localWorkerPool *localWorkerPool
+	// Outbound requests per local worker
+	localWorkerQueues *lwQueues
+	reconciler        reconciler
+
+	// IDs of local workers whose configuration failed to load (protected by configFailedMutex).
+	// Those are not configured again until their configuration changes.
+	configFailedMutex sync.Mutex
+	configFailed      map[string]struct{}
+
+	// Requested states waiting to be written to the state store (protected by pendingStateMutex)
+	pendingStateMutex  sync.Mutex
+	pendingState       map[pendingStateKey][]byte
+	pendingStateSignal chan struct{}
+
+	// Persisted identity of the manager
+	identity string
+	// Configuration generation (protected by generationMutex)
+	generationMutex sync.Mutex
+	generation      uint64
 }
 
 // Run the manager until the given context is cancelled.
@@ -197,15 +284,11 @@ func (m *manager) Run(ctx context.Context) error {
 		log.Debug().Msg("Run finished")
 	}()
 
-	var tcp *listeners.TCP
-	if m.Dependencies.MQTTServer == nil {
-		// Prepare listener
-		tcp = listeners.NewTCP(listeners.Config{
-			ID:      "t1",
-			Address: ":1883",
-		})
-		if err := m.mqttServer.AddListener(tcp); err != nil {
-			return fmt.Errorf("MQTT Listener configuration failed: %w", err)
+	ownsMQTTServer := m.Dependencies.MQTTServer == nil
+	if ownsMQTTServer {
+		// Prepare listeners
+		if err := addMQTTListeners(m.mqttServer, m.MQTT); err != nil {
+			return err
 		}
 		go func() {
 			if err := m.mqttServer.Serve(); err != nil {
@@ -213,20 +296,29 @@ func (m *manager) Run(ctx context.Context) error {
 			}
 		}()
 	}
+	if m.MQTT.Bridge {
+		go m.runMQTTBridge(ctx, log)
+		if m.MQTT.HomeAssistant {
+			go m.runHomeAssistantDiscovery(ctx, log)
+		}
+	}
+	go m.runLivenessMonitor(ctx)
+	go m.runReconciler(ctx, log)
+	go m.runPersister(ctx)
 
 	for {
 		select {
 		case id := <-m.ReconfigureQueue:
 			// Reconfigure worker with id
 			log.Info().Str("id", id).Msg("Reconfiguration detected")
-			m.configChanges.Pub(id)
+			m.setConfigFailed(id, false)
+			m.reconfigureLocalWorker(ctx, id)
 		case <-ctx.Done():
 			// Context cancelled
-			if tcp != nil {
+			m.flushRequests()
+			if ownsMQTTServer {
+				// Closes all listeners
 				m.mqttServer.Close()
-				tcp.Close(func(id string) {
-					// Do nothing
-				})
 			}
 			return nil
 		}
@@ -239,19 +331,43 @@ func (m *manager) GetLocalWorkerInfo(id string) (api.LocalWorkerInfo, string, ti
 	return m.localWorkerPool.GetInfo(id)
 }
 
-// GetAllLocalWorkers fetches the last known info for all local workers.
+// GetAllLocalWorkers fetches the last known info for all local workers that are not offline.
 func (m *manager) GetAllLocalWorkers() []api.LocalWorkerInfo {
 	return m.localWorkerPool.GetAll()
 }
 
+// GetLocalWorkerConfig fetches the configuration requested for a local worker with given ID.
+func (m *manager) GetLocalWorkerConfig(id string) (api.LocalWorkerConfig, bool) {
+	if conf, found := m.localWorkerPool.GetRequest(id); found {
+		return *conf, true
+	}
+	return api.LocalWorkerConfig{}, false
+}
+
+// GetLocalWorkerLiveness returns the liveness of the local worker with given ID.
+func (m *manager) GetLocalWorkerLiveness(id string) (LocalWorkerLiveness, bool) {
+	return m.localWorkerPool.GetLiveness(id)
+}
+
 // SubscribeLocalWorkerRequests is used to subscribe to requested changes of local workers.
-func (m *manager) SubscribeLocalWorkerRequests(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc) {
-	return m.localWorkerPool.SubRequests(enabled, timeout, filter)
+func (m *manager) SubscribeLocalWorkerRequests(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc) {
+	return m.localWorkerPool.SubRequests(enabled, policy, filter)
+}
+
+// AddLocalWorkerRequestWatcher records that the local worker with given ID watches
+// its own requested state from given remote address.
+func (m *manager) AddLocalWorkerRequestWatcher(id, remoteAddr string) context.CancelFunc {
+	return m.localWorkerPool.AddRequestWatcher(id, remoteAddr)
 }
 
 // SubscribeLocalWorkerActuals is used to subscribe to actual changes of local workers.
-func (m *manager) SubscribeLocalWorkerActuals(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc) {
-	return m.localWorkerPool.SubActuals(enabled, timeout, filter)
+func (m *manager) SubscribeLocalWorkerActuals(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.LocalWorker, context.CancelFunc) {
+	return m.localWorkerPool.SubActuals(enabled, policy, filter)
+}
+
+// SubscribeLocalWorkerLiveness is used to subscribe to liveness changes of local workers.
+func (m *manager) SubscribeLocalWorkerLiveness(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan LocalWorkerLivenessInfo, context.CancelFunc) {
+	return m.localWorkerPool.SubLiveness(enabled, policy, filter)
 }
 
 // SetLocalWorkerRequest sets the requested state of a local worker
@@ -261,12 +377,28 @@ func (m *manager) SetLocalWorkerRequest(ctx context.Context, lw api.LocalWorker)
 
 // SetLocalWorkerActual sets the actual state of a local worker
 func (m *manager) SetLocalWorkerActual(ctx context.Context, lw api.LocalWorker, remoteAddr string) error {
-	return m.localWorkerPool.SetActual(ctx, lw, remoteAddr)
+	connected, err := m.localWorkerPool.SetActual(ctx, lw, remoteAddr)
+	if err != nil {
+		return err
+	}
+	if id := lw.GetId(); !m.localWorkerPool.HasRequest(id) && !m.isConfigFailed(id) {
+		// First time we see this local worker, configure it
+		if _, err := m.configureLocalWorker(ctx, id); err != nil {
+			// Do not try again on every heartbeat, wait for the configuration to change
+			m.setConfigFailed(id, true)
+			m.Log.Warn().Err(err).Str("id", id).Msg("Failed to configure local worker")
+		}
+	}
+	if connected {
+		m.replayRequests(lw.GetId())
+	}
+	return nil
 }
 
 // RequestResetLocalWorker requests the local worker with given ID to reset itself.
-func (m *manager) RequestResetLocalWorker(ctx context.Context, id string) {
-	m.localWorkerPool.RequestReset(ctx, id)
+// Returns an error when the local worker cannot be reached or refuses the request.
+func (m *manager) RequestResetLocalWorker(ctx context.Context, id string) error {
+	return m.localWorkerPool.RequestReset(ctx, id)
 }
 
 // Trigger a discovery and wait for the response.
@@ -278,24 +410,16 @@ func (m *manager) Discover(ctx context.Context, id string) (*api.DiscoverResult,
 // Trigger a discovery.
 func (m *manager) SetDevicesDiscoveryRequest(ctx context.Context, req api.DeviceDiscovery) {
 	m.discoverPool.SetDiscoverRequest(req)
-	log := m.Log
-	go func() {
-		lwInfo, _, _, _ := m.localWorkerPool.GetInfo(req.GetId())
-		if lwInfo.GetSupportsSetDeviceDiscoveryRequest() {
-			if client, err := m.localWorkerPool.GetLocalWorkerServiceClient(lwInfo.GetId()); err != nil {
-				log.Error().Err(err).
-					Str("id", lwInfo.GetId()).
-					Msg("Failed to get local worker client")
-			} else {
-				if _, err := client.SetDeviceDiscoveryRequest(context.Background(), &req); err != nil {
-					log.Error().Err(err).
-						Str("id", lwInfo.GetId()).
-						Msg("Failed to send device discovery request to local worker")
-				}
-			}
-		}
-	}()
-
+	id := req.GetId()
+	lwInfo, _, _, found := m.localWorkerPool.GetInfo(id)
+	if !found || !lwInfo.GetSupportsSetDeviceDiscoveryRequest() || m.localWorkerPool.IsOffline(id) {
+		return
+	}
+	m.localWorkerQueues.Enqueue(id, domainDiscovery, id,
+		func(ctx context.Context, client api.LocalWorkerServiceClient) error {
+			_, err := client.SetDeviceDiscoveryRequest(ctx, &req)
+			return err
+		})
 }
 
 // SetDevicesDiscoveryActual is called by the local worker in response to discover requests.
@@ -304,36 +428,53 @@ func (m *manager) SetDevicesDiscoveryActual(ctx context.Context, req api.DeviceD
 }
 
 // Subscribe to discovery requests
-func (m *manager) SubscribeDiscoverRequests(enabled bool, timeout time.Duration, id string) (chan api.DeviceDiscovery, context.CancelFunc) {
-	return m.discoverPool.SubRequests(enabled, timeout, id)
+func (m *manager) SubscribeDiscoverRequests(enabled bool, policy SubscriberPolicy, id string) (chan api.DeviceDiscovery, context.CancelFunc) {
+	return m.discoverPool.SubRequests(enabled, policy, id)
 }
 
 // Subscribe to discovery actuals
-func (m *manager) SubscribeDiscoverActuals(enabled bool, timeout time.Duration, id string) (chan api.DeviceDiscovery, context.CancelFunc) {
-	return m.discoverPool.SubActuals(enabled, timeout, id)
+func (m *manager) SubscribeDiscoverActuals(enabled bool, policy SubscriberPolicy, id string) (chan api.DeviceDiscovery, context.CancelFunc) {
+	return m.discoverPool.SubActuals(enabled, policy, id)
+}
+
+// Get the requested & actual power state
+func (m *manager) GetPower() api.Power {
+	return m.powerPool.Get()
 }
 
 // Set the requested power state
 func (m *manager) SetPowerRequest(x api.PowerState) {
 	m.powerPool.SetRequest(x)
-	log := m.Log
-	go func() {
+	m.persistRequest(state.DomainPower, powerStateKey, &x)
+	m.queueRequest(state.DomainPower, api.GlobalModuleID, powerStateKey, (*api.LocalWorkerInfo).GetSupportsSetPowerRequest,
+		powerRequestSender(x))
+}
+
+// powerRequestSender returns a function that sends the given requested power state to a local worker.
+func powerRequestSender(x api.PowerState) func(context.Context, api.LocalWorkerServiceClient) error {
+	return func(ctx context.Context, client api.LocalWorkerServiceClient) error {
+		_, err := client.SetPowerRequest(ctx, &x)
+		return err
+	}
+}
+
+// queueRequest queues a request for the object with given key, for the local
+// workers of the module with given ID that support it.
+// If the module ID is GlobalModuleID, the request is queued for all local workers
+// that support it.
+func (m *manager) queueRequest(domain state.Domain, moduleID, key string, supported func(*api.LocalWorkerInfo) bool, send func(context.Context, api.LocalWorkerServiceClient) error) {
+	if moduleID == api.GlobalModuleID {
 		for _, lwInfo := range m.localWorkerPool.GetAll() {
-			if lwInfo.GetSupportsSetPowerRequest() {
-				if client, err := m.localWorkerPool.GetLocalWorkerServiceClient(lwInfo.GetId()); err != nil {
-					log.Error().Err(err).
-						Str("id", lwInfo.GetId()).
-						Msg("Failed to get local worker client")
-				} else {
-					if _, err := client.SetPowerRequest(context.Background(), &x); err != nil {
-						log.Error().Err(err).
-							Str("id", lwInfo.GetId()).
-							Msg("Failed to send power request to local worker")
-					}
-				}
+			if supported(&lwInfo) {
+				m.localWorkerQueues.Enqueue(lwInfo.GetId(), domain, key, send)
 			}
 		}
-	}()
+	} else {
+		lwInfo, _, _, _ := m.localWorkerPool.GetInfo(moduleID)
+		if supported(&lwInfo) && !m.localWorkerPool.IsOffline(moduleID) {
+			m.localWorkerQueues.Enqueue(lwInfo.GetId(), domain, key, send)
+		}
+	}
 }
 
 // Set the actual power state
@@ -342,31 +483,41 @@ func (m *manager) SetPowerActual(x api.PowerState) {
 }
 
 // Subscribe to power actuals
-func (m *manager) SubscribePowerActuals(enabled bool, timeout time.Duration) (chan api.Power, context.CancelFunc) {
-	return m.powerPool.SubActual(enabled, timeout)
+func (m *manager) SubscribePowerActuals(enabled bool, policy SubscriberPolicy) (chan api.Power, context.CancelFunc) {
+	return m.powerPool.SubActual(enabled, policy)
+}
+
+// Get the state of the loc with given address
+func (m *manager) GetLoc(addr api.ObjectAddress) (api.Loc, bool) {
+	return m.locPool.Get(addr)
+}
+
+// Get the state of all known locs
+func (m *manager) GetAllLocs() []api.Loc {
+	return m.locPool.GetAll()
 }
 
 // Set the requested loc state
 func (m *manager) SetLocRequest(x api.Loc) {
 	m.locPool.SetRequest(x)
-	log := m.Log
-	go func() {
-		for _, lwInfo := range m.localWorkerPool.GetAll() {
-			if lwInfo.GetSupportsSetLocRequest() {
-				if client, err := m.localWorkerPool.GetLocalWorkerServiceClient(lwInfo.GetId()); err != nil {
-					log.Error().Err(err).
-						Str("id", lwInfo.GetId()).
-						Msg("Failed to get local worker client")
-				} else {
-					if _, err := client.SetLocRequest(context.Background(), &x); err != nil {
-						log.Error().Err(err).
-							Str("id", lwInfo.GetId()).
-							Msg("Failed to send loc request to local worker")
-					}
-				}
-			}
-		}
-	}()
+	m.persistRequest(state.DomainLoc, string(x.GetAddress()), &x)
+	m.sendLocRequest(x)
+}
+
+// sendLocRequest sends the given requested loc state to the local workers that
+// control it.
+func (m *manager) sendLocRequest(x api.Loc) {
+	// Locs are sent to all local workers
+	m.queueRequest(state.DomainLoc, api.GlobalModuleID, string(x.GetAddress()), (*api.LocalWorkerInfo).GetSupportsSetLocRequest,
+		locRequestSender(x))
+}
+
+// locRequestSender returns a function that sends the given requested loc state to a local worker.
+func locRequestSender(x api.Loc) func(context.Context, api.LocalWorkerServiceClient) error {
+	return func(ctx context.Context, client api.LocalWorkerServiceClient) error {
+		_, err := client.SetLocRequest(ctx, &x)
+		return err
+	}
 }
 
 // Set the actual loc state
@@ -375,50 +526,41 @@ func (m *manager) SetLocActual(x api.Loc) {
 }
 
 // Subscribe to loc actuals
-func (m *manager) SubscribeLocActuals(enabled bool, timeout time.Duration) (chan api.Loc, context.CancelFunc) {
-	return m.locPool.SubActual(enabled, timeout)
+func (m *manager) SubscribeLocActuals(enabled bool, policy SubscriberPolicy) (chan api.Loc, context.CancelFunc) {
+	return m.locPool.SubActual(enabled, policy, "")
+}
+
+// Get the state of the output with given address
+func (m *manager) GetOutput(addr api.ObjectAddress) (api.Output, bool) {
+	return m.outputPool.Get(addr)
+}
+
+// Get the state of all known outputs
+func (m *manager) GetAllOutputs() []api.Output {
+	return m.outputPool.GetAll()
 }
 
 // Set the requested output state
 func (m *manager) SetOutputRequest(x api.Output) {
 	m.outputPool.SetRequest(x)
-	log := m.Log
-	go func() {
-		moduleID, _, _ := api.SplitAddress(x.Address)
-		if moduleID == api.GlobalModuleID {
-			// Send to all
-			for _, lwInfo := range m.localWorkerPool.GetAll() {
-				if lwInfo.GetSupportsSetOutputRequest() {
-					if client, err := m.localWorkerPool.GetLocalWorkerServiceClient(lwInfo.GetId()); err != nil {
-						log.Error().Err(err).
-							Str("id", lwInfo.GetId()).
-							Msg("Failed to get local worker client")
-					} else {
-						if _, err := client.SetOutputRequest(context.Background(), &x); err != nil {
-							log.Error().Err(err).
-								Str("id", lwInfo.GetId()).
-								Msg("Failed to send output request to local worker")
-						}
-					}
-				}
-			}
-		} else {
-			lwInfo, _, _, _ := m.localWorkerPool.GetInfo(moduleID)
-			if lwInfo.GetSupportsSetOutputRequest() {
-				if client, err := m.localWorkerPool.GetLocalWorkerServiceClient(lwInfo.GetId()); err != nil {
-					log.Error().Err(err).
-						Str("id", lwInfo.GetId()).
-						Msg("Failed to get local worker client")
-				} else {
-					if _, err := client.SetOutputRequest(context.Background(), &x); err != nil {
-						log.Error().Err(err).
-							Str("id", lwInfo.GetId()).
-							Msg("Failed to send output request to local worker")
-					}
-				}
-			}
-		}
-	}()
+	m.persistRequest(state.DomainOutput, string(x.GetAddress()), &x)
+	m.sendOutputRequest(x)
+}
+
+// sendOutputRequest sends the given requested output state to the local workers that
+// control it.
+func (m *manager) sendOutputRequest(x api.Output) {
+	moduleID, _, _ := api.SplitAddress(x.Address)
+	m.queueRequest(state.DomainOutput, moduleID, string(x.GetAddress()), (*api.LocalWorkerInfo).GetSupportsSetOutputRequest,
+		outputRequestSender(x))
+}
+
+// outputRequestSender returns a function that sends the given requested output state to a local worker.
+func outputRequestSender(x api.Output) func(context.Context, api.LocalWorkerServiceClient) error {
+	return func(ctx context.Context, client api.LocalWorkerServiceClient) error {
+		_, err := client.SetOutputRequest(ctx, &x)
+		return err
+	}
 }
 
 // Set the actual output state
@@ -427,8 +569,18 @@ func (m *manager) SetOutputActual(x api.Output) {
 }
 
 // Subscribe to output actuals
-func (m *manager) SubscribeOutputActuals(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.Output, context.CancelFunc) {
-	return m.outputPool.SubActual(enabled, timeout, filter)
+func (m *manager) SubscribeOutputActuals(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.Output, context.CancelFunc) {
+	return m.outputPool.SubActual(enabled, policy, filter)
+}
+
+// Get the state of the sensor with given address
+func (m *manager) GetSensor(addr api.ObjectAddress) (api.Sensor, bool) {
+	return m.sensorPool.Get(addr)
+}
+
+// Get the state of all known sensors
+func (m *manager) GetAllSensors() []api.Sensor {
+	return m.sensorPool.GetAll()
 }
 
 // Set the actual sensor state
@@ -437,50 +589,41 @@ func (m *manager) SetSensorActual(x api.Sensor) {
 }
 
 // Subscribe to sensor actuals
-func (m *manager) SubscribeSensorActuals(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.Sensor, context.CancelFunc) {
-	return m.sensorPool.SubActual(enabled, timeout, filter)
+func (m *manager) SubscribeSensorActuals(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.Sensor, context.CancelFunc) {
+	return m.sensorPool.SubActual(enabled, policy, filter)
+}
+
+// Get the state of the switch with given address
+func (m *manager) GetSwitch(addr api.ObjectAddress) (api.Switch, bool) {
+	return m.switchPool.Get(addr)
+}
+
+// Get the state of all known switches
+func (m *manager) GetAllSwitches() []api.Switch {
+	return m.switchPool.GetAll()
 }
 
 // Set the requested switch state
 func (m *manager) SetSwitchRequest(x api.Switch) {
 	m.switchPool.SetRequest(x)
-	log := m.Log
-	go func() {
-		moduleID, _, _ := api.SplitAddress(x.Address)
-		if moduleID == api.GlobalModuleID {
-			// Send to all
-			for _, lwInfo := range m.localWorkerPool.GetAll() {
-				if lwInfo.GetSupportsSetSwitchRequest() {
-					if client, err := m.localWorkerPool.GetLocalWorkerServiceClient(lwInfo.GetId()); err != nil {
-						log.Error().Err(err).
-							Str("id", lwInfo.GetId()).
-							Msg("Failed to get local worker client")
-					} else {
-						if _, err := client.SetSwitchRequest(context.Background(), &x); err != nil {
-							log.Error().Err(err).
-								Str("id", lwInfo.GetId()).
-								Msg("Failed to send switch request to local worker")
-						}
-					}
-				}
-			}
-		} else {
-			lwInfo, _, _, _ := m.localWorkerPool.GetInfo(moduleID)
-			if lwInfo.GetSupportsSetSwitchRequest() {
-				if client, err := m.localWorkerPool.GetLocalWorkerServiceClient(lwInfo.GetId()); err != nil {
-					log.Error().Err(err).
-						Str("id", lwInfo.GetId()).
-						Msg("Failed to get local worker client")
-				} else {
-					if _, err := client.SetSwitchRequest(context.Background(), &x); err != nil {
-						log.Error().Err(err).
-							Str("id", lwInfo.GetId()).
-							Msg("Failed to send switch request to local worker")
-					}
-				}
-			}
-		}
-	}()
+	m.persistRequest(state.DomainSwitch, string(x.GetAddress()), &x)
+	m.sendSwitchRequest(x)
+}
+
+// sendSwitchRequest sends the given requested switch state to the local workers that
+// control it.
+func (m *manager) sendSwitchRequest(x api.Switch) {
+	moduleID, _, _ := api.SplitAddress(x.Address)
+	m.queueRequest(state.DomainSwitch, moduleID, string(x.GetAddress()), (*api.LocalWorkerInfo).GetSupportsSetSwitchRequest,
+		switchRequestSender(x))
+}
+
+// switchRequestSender returns a function that sends the given requested switch state to a local worker.
+func switchRequestSender(x api.Switch) func(context.Context, api.LocalWorkerServiceClient) error {
+	return func(ctx context.Context, client api.LocalWorkerServiceClient) error {
+		_, err := client.SetSwitchRequest(ctx, &x)
+		return err
+	}
 }
 
 // Set the actual switch state
@@ -489,8 +632,13 @@ func (m *manager) SetSwitchActual(x api.Switch) {
 }
 
 // Subscribe to switch actuals
-func (m *manager) SubscribeSwitchActuals(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.Switch, context.CancelFunc) {
-	return m.switchPool.SubActual(enabled, timeout, filter)
+func (m *manager) SubscribeSwitchActuals(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan api.Switch, context.CancelFunc) {
+	return m.switchPool.SubActual(enabled, policy, filter)
+}
+
+// Get the actual clock state
+func (m *manager) GetClock() api.Clock {
+	return m.clockPool.Get()
 }
 
 // Set the actual clock state
@@ -499,6 +647,6 @@ func (m *manager) SetClockActual(x api.Clock) {
 }
 
 // Subscribe to clock actuals
-func (m *manager) SubscribeClockActuals(enabled bool, timeout time.Duration) (chan api.Clock, context.CancelFunc) {
-	return m.clockPool.SubActual(enabled, timeout)
+func (m *manager) SubscribeClockActuals(enabled bool, policy SubscriberPolicy) (chan api.Clock, context.CancelFunc) {
+	return m.clockPool.SubActual(enabled, policy)
 }
@@ -60,6 +60,58 @@ var (
 		"loc_direction",
 		"Current direction per loc address [1=forward, -1=backwards]",
 		"address")
+
+	// Number of reconfigurations per local worker
+	lwReconfigureTotalCounters = metrics.MustRegisterCounterVec(subSystem,
+		"lw_reconfigure_total",
+		"Number of reconfigurations per local worker",
+		"id")
+	// Liveness per local worker [1=current liveness, 0=otherwise]
+	lwLivenessGauges = metrics.MustRegisterGaugeVec(subSystem,
+		"lw_liveness",
+		"Liveness per local worker [1=current liveness, 0=otherwise]",
+		"id", "liveness")
+	// Number of requests waiting in the outbound queue per local worker
+	lwQueueDepthGauges = metrics.MustRegisterGaugeVec(subSystem,
+		"lw_queue_depth",
+		"Number of requests waiting in the outbound queue per local worker",
+		"id")
+	// Number of queued requests replaced by a newer request for the same object, per local worker
+	lwQueueCoalescedTotalCounters = metrics.MustRegisterCounterVec(subSystem,
+		"lw_queue_coalesced_total",
+		"Number of queued requests replaced by a newer request for the same object, per local worker",
+		"id")
+	// Number of requests dropped because the outbound queue was full, per local worker
+	lwQueueDroppedTotalCounters = metrics.MustRegisterCounterVec(subSystem,
+		"lw_queue_dropped_total",
+		"Number of requests dropped because the outbound queue was full, per local worker",
+		"id")
+	// Time between queueing a request & its delivery to a local worker
+	lwRequestLatency = metrics.MustRegisterHistogramVec(subSystem,
+		"lw_request_latency_seconds",
+		"Time between queueing a request & its delivery to a local worker",
+		prometheus.ExponentialBuckets(0.001, 4, 8),
+		"id", "domain")
+	// Number of requests that could not be delivered per local worker
+	lwRequestFailedTotalCounters = metrics.MustRegisterCounterVec(subSystem,
+		"lw_request_failed_total",
+		"Number of requests that could not be delivered per local worker",
+		"id", "domain")
+	// Number of objects of which the actual state differs from the requested state, per domain
+	divergentObjectsGauges = metrics.MustRegisterGaugeVec(subSystem,
+		"divergent_objects",
+		"Number of objects of which the actual state differs from the requested state, per domain",
+		"domain")
+	// Number of requests resent because the actual state differs from the requested state
+	reconcileResendTotalCounters = metrics.MustRegisterCounterVec(subSystem,
+		"reconcile_resend_total",
+		"Number of requests resent because the actual state differs from the requested state",
+		"domain", "address")
+	// Number of failed reconfigurations per local worker
+	lwReconfigureFailedTotalCounters = metrics.MustRegisterCounterVec(subSystem,
+		"lw_reconfigure_failed_total",
+		"Number of failed reconfigurations per local worker",
+		"id")
 )
 
 func newPoolMetrics(pool string) poolMetrics {
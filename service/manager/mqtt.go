@@ -0,0 +1,135 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultMQTTAddress is the default address of the plain MQTT listener
+	DefaultMQTTAddress = ":1883"
+	// DefaultMQTTTopicPrefix is the default prefix of local worker topics
+	DefaultMQTTTopicPrefix = "binkynet/"
+)
+
+// MQTTConfig holds the configuration of the embedded MQTT server.
+type MQTTConfig struct {
+	// Address of the plain TCP listener (empty to disable)
+	Address string
+	// Address of the TLS listener (empty to disable)
+	TLSAddress string
+	// Certificate & key file used by the TLS listener
+	TLSCertFile string
+	TLSKeyFile  string
+	// Address of the websocket listener (empty to disable)
+	WebsocketAddress string
+	// Path of the credentials file.
+	// If empty, all clients are allowed to connect & use all topics.
+	CredentialsFile string
+	// Prefix of the topics of local workers.
+	// Local workers can only use topics below <TopicPrefix><worker-id>/.
+	TopicPrefix string
+	// If set, actual states are published & requests are accepted
+	// on MQTT topics below TopicPrefix.
+	// Requires CredentialsFile (unless an MQTT server is passed in the dependencies).
+	Bridge bool
+	// If set, Home Assistant discovery messages are published for the
+	// objects of all local workers (requires Bridge).
+	HomeAssistant bool
+	// Discovery prefix of Home Assistant
+	HomeAssistantPrefix string
+}
+
+// NewMQTTServer creates a new MQTT server with given configuration.
+func NewMQTTServer(conf MQTTConfig, log zerolog.Logger) (*mqtt.Server, error) {
+	options := &mqtt.Options{
+		InlineClient: true,
+	}
+	mqttServer := mqtt.New(options)
+	// For security reasons, the default implementation disallows all connections.
+	// If you want to allow all connections, you must specifically allow it.
+	if conf.CredentialsFile == "" {
+		log.Warn().Msg("No MQTT credentials configured, allowing all MQTT clients")
+		if err := mqttServer.AddHook(new(auth.AllowHook), nil); err != nil {
+			return nil, fmt.Errorf("MQTT Hook configuration failed: %w", err)
+		}
+		return mqttServer, nil
+	}
+	creds, err := loadMQTTCredentials(conf.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	topicPrefix := conf.TopicPrefix
+	if topicPrefix == "" {
+		topicPrefix = DefaultMQTTTopicPrefix
+	}
+	if err := mqttServer.AddHook(newMQTTAuthHook(creds, topicPrefix, log), nil); err != nil {
+		return nil, fmt.Errorf("MQTT Hook configuration failed: %w", err)
+	}
+	return mqttServer, nil
+}
+
+// addMQTTListeners adds all listeners configured in the given configuration
+// to the given server.
+func addMQTTListeners(mqttServer *mqtt.Server, conf MQTTConfig) error {
+	var tlsConfig *tls.Config
+	if conf.TLSAddress != "" || (conf.WebsocketAddress != "" && conf.TLSCertFile != "") {
+		cert, err := tls.LoadX509KeyPair(conf.TLSCertFile, conf.TLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load MQTT TLS certificate: %w", err)
+		}
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+	var all []listeners.Listener
+	if conf.Address != "" {
+		all = append(all, listeners.NewTCP(listeners.Config{
+			ID:      "t1",
+			Address: conf.Address,
+		}))
+	}
+	if conf.TLSAddress != "" {
+		all = append(all, listeners.NewTCP(listeners.Config{
+			ID:        "tls1",
+			Address:   conf.TLSAddress,
+			TLSConfig: tlsConfig,
+		}))
+	}
+	if conf.WebsocketAddress != "" {
+		all = append(all, listeners.NewWebsocket(listeners.Config{
+			ID:        "ws1",
+			Address:   conf.WebsocketAddress,
+			TLSConfig: tlsConfig,
+		}))
+	}
+	if len(all) == 0 {
+		return fmt.Errorf("no MQTT listener configured")
+	}
+	for _, l := range all {
+		if err := mqttServer.AddListener(l); err != nil {
+			return fmt.Errorf("MQTT Listener configuration failed: %w", err)
+		}
+	}
+	return nil
+}
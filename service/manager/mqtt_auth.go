@@ -0,0 +1,131 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/rs/zerolog"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// mqttCredentials is the content of an MQTT credentials file:
+//
+//	# Local workers connect with their ID as username.
+//	workers:
+//	  9381a8f378: secret1
+//	# Admins can use all topics.
+//	admins:
+//	  dashboard: secret2
+type mqttCredentials struct {
+	// Password per local worker ID
+	Workers map[string]string `yaml:"workers"`
+	// Password per admin username
+	Admins map[string]string `yaml:"admins"`
+}
+
+// loadMQTTCredentials reads the MQTT credentials file at given path.
+func loadMQTTCredentials(path string) (mqttCredentials, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return mqttCredentials{}, fmt.Errorf("failed to read MQTT credentials: %w", err)
+	}
+	var creds mqttCredentials
+	if err := yaml.UnmarshalStrict(content, &creds); err != nil {
+		return mqttCredentials{}, fmt.Errorf("failed to parse MQTT credentials in %s: %w", path, err)
+	}
+	for username := range creds.Admins {
+		if _, found := creds.Workers[username]; found {
+			return mqttCredentials{}, fmt.Errorf("MQTT user '%s' is both a worker and an admin in %s", username, path)
+		}
+	}
+	return creds, nil
+}
+
+// mqttAuthHook is an MQTT hook that authenticates clients using a credentials file
+// and restricts local workers to topics below their own topic prefix.
+type mqttAuthHook struct {
+	mqtt.HookBase
+	creds       mqttCredentials
+	topicPrefix string
+	log         zerolog.Logger
+}
+
+// newMQTTAuthHook creates a new authentication hook.
+func newMQTTAuthHook(creds mqttCredentials, topicPrefix string, log zerolog.Logger) *mqttAuthHook {
+	return &mqttAuthHook{
+		creds:       creds,
+		topicPrefix: topicPrefix,
+		log:         log.With().Str("component", "mqtt-auth").Logger(),
+	}
+}
+
+// ID returns the ID of the hook.
+func (h *mqttAuthHook) ID() string {
+	return "binkynet-auth"
+}
+
+// Provides indicates which hook methods this hook provides.
+func (h *mqttAuthHook) Provides(b byte) bool {
+	return bytes.Contains([]byte{
+		mqtt.OnConnectAuthenticate,
+		mqtt.OnACLCheck,
+	}, []byte{b})
+}
+
+// OnConnectAuthenticate returns true if the client provides a known username
+// with a matching password.
+func (h *mqttAuthHook) OnConnectAuthenticate(cl *mqtt.Client, pk packets.Packet) bool {
+	username := string(pk.Connect.Username)
+	password, found := h.creds.Workers[username]
+	if !found {
+		password, found = h.creds.Admins[username]
+	}
+	if !found || subtle.ConstantTimeCompare([]byte(password), pk.Connect.Password) != 1 {
+		h.log.Warn().
+			Str("username", username).
+			Str("remote", cl.Net.Remote).
+			Msg("MQTT client failed to authenticate")
+		return false
+	}
+	return true
+}
+
+// OnACLCheck returns true if the client is allowed to use the given topic (filter).
+// Admins can use all topics, local workers only topics below their own prefix.
+func (h *mqttAuthHook) OnACLCheck(cl *mqtt.Client, topic string, write bool) bool {
+	username := string(cl.Properties.Username)
+	if _, found := h.creds.Admins[username]; found {
+		return true
+	}
+	if _, found := h.creds.Workers[username]; !found {
+		return false
+	}
+	if strings.HasPrefix(topic, h.topicPrefix+username+"/") {
+		return true
+	}
+	h.log.Debug().
+		Str("username", username).
+		Str("topic", topic).
+		Bool("write", write).
+		Msg("MQTT client denied access to topic")
+	return false
+}
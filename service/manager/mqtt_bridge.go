@@ -0,0 +1,206 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/rs/zerolog"
+)
+
+// The MQTT bridge uses the following topics (below the topic prefix):
+//
+//	<module>/<domain>/<local>/actual   Actual state (retained), published by the manager
+//	<module>/<domain>/<local>/request  Requested state, published by clients
+//	GLOBAL/power/actual                Actual power state (retained), published by the manager
+//	GLOBAL/power/request               Requested power state, published by clients
+//	GLOBAL/clock/actual                Actual clock (retained), published by the manager
+//
+// Where <domain> is one of loc, output, sensor & switch and <module>/<local>
+// is the address of the object.
+// Payloads are the JSON encoded state messages of the BinkyNet API,
+// e.g. `{"direction":"OFF"}` for a switch.
+// Actual states of sensors cannot be requested.
+
+const (
+	mqttDomainLoc    = "loc"
+	mqttDomainOutput = "output"
+	mqttDomainPower  = "power"
+	mqttDomainSensor = "sensor"
+	mqttDomainSwitch = "switch"
+	mqttDomainClock  = "clock"
+
+	mqttTopicActual  = "actual"
+	mqttTopicRequest = "request"
+
+	// Identifier of inline subscriptions of the bridge
+	mqttBridgeSubscriptionID = 1
+	// Policy used to deliver changes to the bridge.
+	// Only the latest state of every object has to be published.
+	mqttBridgePolicy = PolicyCoalesce
+)
+
+var (
+	mqttBridgeMarshaler = jsonpb.Marshaler{OrigName: true, EmitDefaults: true}
+)
+
+// mqttTopicPrefix returns the prefix of all topics used by the manager.
+func (m *manager) mqttTopicPrefix() string {
+	if m.MQTT.TopicPrefix == "" {
+		return DefaultMQTTTopicPrefix
+	}
+	return m.MQTT.TopicPrefix
+}
+
+// mqttObjectTopic returns the topic of the object with given address.
+func mqttObjectTopic(prefix string, addr api.ObjectAddress, domain, kind string) string {
+	module, local, _ := api.SplitAddress(addr)
+	return prefix + strings.Join([]string{module, domain, local, kind}, "/")
+}
+
+// mqttGlobalTopic returns the topic of a global (address-less) domain.
+func mqttGlobalTopic(prefix, domain, kind string) string {
+	return prefix + strings.Join([]string{api.GlobalModuleID, domain, kind}, "/")
+}
+
+// runMQTTBridge publishes all actual changes of the pools on MQTT
+// and forwards requests received on MQTT to the pools, until the given
+// context is cancelled.
+func (m *manager) runMQTTBridge(ctx context.Context, log zerolog.Logger) {
+	log = log.With().Str("component", "mqtt-bridge").Logger()
+	prefix := m.mqttTopicPrefix()
+
+	// Subscribe to requests
+	onRequest := func(cl *mqtt.Client, sub packets.Subscription, pk packets.Packet) {
+		if len(pk.Payload) == 0 {
+			// Removal of retained message
+			return
+		}
+		if err := m.handleMQTTRequest(prefix, pk.TopicName, pk.Payload); err != nil {
+			log.Warn().Err(err).Str("topic", pk.TopicName).Msg("Invalid MQTT request")
+		}
+	}
+	filters := []string{
+		prefix + "+/+/+/" + mqttTopicRequest,
+		mqttGlobalTopic(prefix, mqttDomainPower, mqttTopicRequest),
+	}
+	for _, filter := range filters {
+		if err := m.mqttServer.Subscribe(filter, mqttBridgeSubscriptionID, onRequest); err != nil {
+			log.Error().Err(err).Str("filter", filter).Msg("Failed to subscribe to MQTT requests")
+			return
+		}
+		defer m.mqttServer.Unsubscribe(filter, mqttBridgeSubscriptionID)
+	}
+
+	// Publish actual changes
+	publish := func(topic string, msg proto.Message) {
+		payload, err := mqttBridgeMarshaler.MarshalToString(msg)
+		if err != nil {
+			log.Warn().Err(err).Str("topic", topic).Msg("Failed to encode MQTT message")
+			return
+		}
+		if err := m.mqttServer.Publish(topic, []byte(payload), true, 0); err != nil {
+			log.Warn().Err(err).Str("topic", topic).Msg("Failed to publish MQTT message")
+		}
+	}
+	lch, lcancel := m.locPool.SubActual(true, mqttBridgePolicy, "")
+	defer lcancel()
+	och, ocancel := m.outputPool.SubActual(true, mqttBridgePolicy, "")
+	defer ocancel()
+	pch, pcancel := m.powerPool.SubActual(true, mqttBridgePolicy)
+	defer pcancel()
+	sch, scancel := m.sensorPool.SubActual(true, mqttBridgePolicy, "")
+	defer scancel()
+	swch, swcancel := m.switchPool.SubActual(true, mqttBridgePolicy, "")
+	defer swcancel()
+	cch, ccancel := m.clockPool.SubActual(true, mqttBridgePolicy)
+	defer ccancel()
+	for {
+		select {
+		case x := <-lch:
+			if x.GetActual() != nil {
+				publish(mqttObjectTopic(prefix, x.GetAddress(), mqttDomainLoc, mqttTopicActual), x.GetActual())
+			}
+		case x := <-och:
+			if x.GetActual() != nil {
+				publish(mqttObjectTopic(prefix, x.GetAddress(), mqttDomainOutput, mqttTopicActual), x.GetActual())
+			}
+		case x := <-pch:
+			if x.GetActual() != nil {
+				publish(mqttGlobalTopic(prefix, mqttDomainPower, mqttTopicActual), x.GetActual())
+			}
+		case x := <-sch:
+			if x.GetActual() != nil {
+				publish(mqttObjectTopic(prefix, x.GetAddress(), mqttDomainSensor, mqttTopicActual), x.GetActual())
+			}
+		case x := <-swch:
+			if x.GetActual() != nil {
+				publish(mqttObjectTopic(prefix, x.GetAddress(), mqttDomainSwitch, mqttTopicActual), x.GetActual())
+			}
+		case x := <-cch:
+			publish(mqttGlobalTopic(prefix, mqttDomainClock, mqttTopicActual), &x)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleMQTTRequest decodes a request received on the given topic and
+// passes it to the manager.
+func (m *manager) handleMQTTRequest(prefix, topic string, payload []byte) error {
+	parts := strings.Split(strings.TrimPrefix(topic, prefix), "/")
+	if len(parts) == 3 && parts[0] == api.GlobalModuleID && parts[1] == mqttDomainPower {
+		var x api.PowerState
+		if err := jsonpb.UnmarshalString(string(payload), &x); err != nil {
+			return err
+		}
+		m.SetPowerRequest(x)
+		return nil
+	}
+	if len(parts) != 4 || parts[3] != mqttTopicRequest {
+		return fmt.Errorf("unknown topic")
+	}
+	addr := api.JoinModuleLocal(parts[0], parts[2])
+	switch parts[1] {
+	case mqttDomainLoc:
+		var x api.LocState
+		if err := jsonpb.UnmarshalString(string(payload), &x); err != nil {
+			return err
+		}
+		m.SetLocRequest(api.Loc{Address: addr, Request: &x})
+	case mqttDomainOutput:
+		var x api.OutputState
+		if err := jsonpb.UnmarshalString(string(payload), &x); err != nil {
+			return err
+		}
+		m.SetOutputRequest(api.Output{Address: addr, Request: &x})
+	case mqttDomainSwitch:
+		var x api.SwitchState
+		if err := jsonpb.UnmarshalString(string(payload), &x); err != nil {
+			return err
+		}
+		m.SetSwitchRequest(api.Switch{Address: addr, Request: &x})
+	default:
+		return fmt.Errorf("cannot request %s state", parts[1])
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/rs/zerolog"
+)
+
+// Home Assistant discovers entities using retained configuration messages
+// published on <discovery-prefix><component>/<node-id>/<object-id>/config.
+// The manager publishes these messages for the objects in the configuration
+// of every local worker it knows, using the topics of the MQTT bridge:
+//
+//	binary-sensor               -> binary_sensor
+//	binary-output               -> switch
+//	relay-switch, servo-switch  -> select (STRAIGHT, OFF)
+//	global power                -> switch
+
+const (
+	// DefaultHomeAssistantPrefix is the default discovery prefix of Home Assistant
+	DefaultHomeAssistantPrefix = "homeassistant/"
+
+	haComponentBinarySensor = "binary_sensor"
+	haComponentSelect       = "select"
+	haComponentSwitch       = "switch"
+)
+
+var (
+	// Characters that are not allowed in node & object IDs of discovery topics
+	haInvalidIDChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+)
+
+// haEntity is the discovery payload of a single Home Assistant entity.
+type haEntity struct {
+	Name            string   `json:"name"`
+	UniqueID        string   `json:"unique_id"`
+	Device          haDevice `json:"device"`
+	StateTopic      string   `json:"state_topic"`
+	ValueTemplate   string   `json:"value_template,omitempty"`
+	CommandTopic    string   `json:"command_topic,omitempty"`
+	CommandTemplate string   `json:"command_template,omitempty"`
+	PayloadOn       string   `json:"payload_on,omitempty"`
+	PayloadOff      string   `json:"payload_off,omitempty"`
+	StateOn         string   `json:"state_on,omitempty"`
+	StateOff        string   `json:"state_off,omitempty"`
+	Options         []string `json:"options,omitempty"`
+}
+
+// haDevice groups the entities of a single local worker (or the manager itself).
+type haDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+}
+
+// haID returns the given parts joined into an ID that is valid in discovery topics.
+func haID(parts ...string) string {
+	return haInvalidIDChars.ReplaceAllString(strings.Join(parts, "_"), "_")
+}
+
+// homeAssistantPrefix returns the discovery prefix of Home Assistant.
+func (m *manager) homeAssistantPrefix() string {
+	if m.MQTT.HomeAssistantPrefix == "" {
+		return DefaultHomeAssistantPrefix
+	}
+	return m.MQTT.HomeAssistantPrefix
+}
+
+// runHomeAssistantDiscovery publishes discovery messages for the global power
+// and for the objects of all local workers, every time the configuration
+// of a local worker changes, until the given context is cancelled.
+func (m *manager) runHomeAssistantDiscovery(ctx context.Context, log zerolog.Logger) {
+	log = log.With().Str("component", "homeassistant").Logger()
+	prefix := m.mqttTopicPrefix()
+	discoveryPrefix := m.homeAssistantPrefix()
+
+	publish := func(topic string, payload []byte) {
+		if err := m.mqttServer.Publish(topic, payload, true, 0); err != nil {
+			log.Warn().Err(err).Str("topic", topic).Msg("Failed to publish discovery message")
+		}
+	}
+	publishEntity := func(component, nodeID, objectID string, entity haEntity) string {
+		topic := discoveryPrefix + strings.Join([]string{component, nodeID, objectID, "config"}, "/")
+		payload, err := json.Marshal(entity)
+		if err != nil {
+			log.Warn().Err(err).Str("topic", topic).Msg("Failed to encode discovery message")
+			return topic
+		}
+		publish(topic, payload)
+		return topic
+	}
+
+	// Global power
+	nodeID := haID("binkynet", m.identity)
+	publishEntity(haComponentSwitch, nodeID, "power", haEntity{
+		Name:     "Power",
+		UniqueID: haID("binkynet", m.identity, "power"),
+		Device: haDevice{
+			Identifiers:  []string{nodeID},
+			Name:         "BinkyNet Network Manager",
+			Manufacturer: "BinkyNet",
+			Model:        "Network Manager",
+		},
+		StateTopic:    mqttGlobalTopic(prefix, mqttDomainPower, mqttTopicActual),
+		ValueTemplate: "{{ 'ON' if value_json.enabled else 'OFF' }}",
+		CommandTopic:  mqttGlobalTopic(prefix, mqttDomainPower, mqttTopicRequest),
+		PayloadOn:     `{"enabled":true}`,
+		PayloadOff:    `{"enabled":false}`,
+		StateOn:       "ON",
+		StateOff:      "OFF",
+	})
+
+	// Objects of local workers
+	published := make(map[string]map[string]struct{}) // worker ID -> topics
+	hashes := make(map[string]string)                 // worker ID -> config hash
+	ch, cancel := m.localWorkerPool.SubRequests(true, mqttBridgePolicy, "")
+	defer cancel()
+	for {
+		select {
+		case lw := <-ch:
+			id := lw.GetId()
+			conf := lw.GetRequest()
+			if hash := conf.GetHash(); hashes[id] == hash {
+				// No changes
+				continue
+			} else {
+				hashes[id] = hash
+			}
+			topics := make(map[string]struct{})
+			for component, entities := range haWorkerEntities(prefix, id, conf) {
+				for objectID, entity := range entities {
+					topics[publishEntity(component, haID("binkynet", id), objectID, entity)] = struct{}{}
+				}
+			}
+			// Remove entities of objects that no longer exist
+			for topic := range published[id] {
+				if _, found := topics[topic]; !found {
+					publish(topic, nil)
+				}
+			}
+			published[id] = topics
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// haWorkerEntities returns the entities (by component & object ID) for the
+// objects in the configuration of the local worker with given ID.
+func haWorkerEntities(prefix, id string, conf *api.LocalWorkerConfig) map[string]map[string]haEntity {
+	device := haDevice{
+		Identifiers:  []string{haID("binkynet", id)},
+		Name:         id,
+		Manufacturer: "BinkyNet",
+		Model:        "Local Worker",
+	}
+	if alias := conf.GetAlias(); alias != "" {
+		device.Name = alias
+	}
+	result := make(map[string]map[string]haEntity)
+	add := func(component string, obj *api.Object, entity haEntity) {
+		objectID := haID(string(obj.GetId()))
+		entity.Name = string(obj.GetId())
+		entity.UniqueID = haID("binkynet", id, string(obj.GetId()))
+		entity.Device = device
+		if result[component] == nil {
+			result[component] = make(map[string]haEntity)
+		}
+		result[component][objectID] = entity
+	}
+	for _, obj := range conf.GetObjects() {
+		addr := api.JoinModuleLocal(id, string(obj.GetId()))
+		switch obj.GetType() {
+		case api.ObjectTypeBinarySensor:
+			add(haComponentBinarySensor, obj, haEntity{
+				StateTopic:    mqttObjectTopic(prefix, addr, mqttDomainSensor, mqttTopicActual),
+				ValueTemplate: "{{ 'ON' if value_json.value else 'OFF' }}",
+			})
+		case api.ObjectTypeBinaryOutput:
+			add(haComponentSwitch, obj, haEntity{
+				StateTopic:    mqttObjectTopic(prefix, addr, mqttDomainOutput, mqttTopicActual),
+				ValueTemplate: "{{ 'ON' if value_json.value else 'OFF' }}",
+				CommandTopic:  mqttObjectTopic(prefix, addr, mqttDomainOutput, mqttTopicRequest),
+				PayloadOn:     `{"value":1}`,
+				PayloadOff:    `{"value":0}`,
+				StateOn:       "ON",
+				StateOff:      "OFF",
+			})
+		case api.ObjectTypeRelaySwitch, api.ObjectTypeServoSwitch:
+			add(haComponentSelect, obj, haEntity{
+				StateTopic:      mqttObjectTopic(prefix, addr, mqttDomainSwitch, mqttTopicActual),
+				ValueTemplate:   "{{ value_json.direction }}",
+				CommandTopic:    mqttObjectTopic(prefix, addr, mqttDomainSwitch, mqttTopicRequest),
+				CommandTemplate: `{"direction":"{{ value }}"}`,
+				Options:         []string{api.SwitchDirection_STRAIGHT.String(), api.SwitchDirection_OFF.String()},
+			})
+		}
+	}
+	return result
+}
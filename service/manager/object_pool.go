@@ -0,0 +1,214 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"sync"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/rs/zerolog"
+)
+
+// poolObject is implemented by pointers to the objects kept in an objectPool.
+type poolObject[T any] interface {
+	*T
+	GetAddress() api.ObjectAddress
+	Clone() *T
+}
+
+// objectPoolOptions describes how an objectPool handles objects of type T.
+type objectPoolOptions[T any] struct {
+	// Name of the domain (used in logs)
+	name string
+	// Metrics of the pool
+	metrics poolMetrics
+	// Returns true if the given object has a requested state.
+	// Nil for domains that cannot be requested.
+	hasRequest func(x *T) bool
+	// Copies (a clone of) the requested state of source into target.
+	// Nil for domains that cannot be requested.
+	setRequest func(target, source *T)
+	// Returns true if the given object has an actual state.
+	hasActual func(x *T) bool
+	// Copies (a clone of) the actual state of source into target.
+	setActual func(target, source *T)
+	// Called after a requested state has been set (optional).
+	onRequest func(x *T)
+}
+
+// objectPool keeps the requested & actual states of objects of a single domain,
+// by address.
+//
+// An actual state for an unknown address is stored with the object that has
+// the same local address in the global module (if any).
+// Domains that can be requested only keep actual states of objects that have
+// been requested, other domains keep all actual states.
+type objectPool[T any, P poolObject[T]] struct {
+	mutex         sync.RWMutex
+	log           zerolog.Logger
+	options       objectPoolOptions[T]
+	entries       map[api.ObjectAddress]*T
+	actualChanges *broadcaster[T]
+}
+
+// newObjectPool creates a new pool using the given options.
+func newObjectPool[T any, P poolObject[T]](log zerolog.Logger, options objectPoolOptions[T]) *objectPool[T, P] {
+	log = log.With().Str("pool", options.name).Logger()
+	address := func(x T) string { return string(P(&x).GetAddress()) }
+	return &objectPool[T, P]{
+		log:     log,
+		options: options,
+		entries: make(map[api.ObjectAddress]*T),
+		actualChanges: newBroadcaster(log, broadcasterOptions[T]{
+			name: options.name + "-actuals",
+			key:  address,
+			onDelivered: func(x T) {
+				options.metrics.SubActualMessagesTotalCounters.WithLabelValues(address(x)).Inc()
+			},
+			onDropped: func(x T) {
+				options.metrics.SubActualMessagesFailedTotalCounters.WithLabelValues(address(x)).Inc()
+			},
+		}),
+	}
+}
+
+// canRequest returns true if objects of this pool can be requested.
+func (p *objectPool[T, P]) canRequest() bool {
+	return p.options.setRequest != nil
+}
+
+// clone returns a clone of the given object.
+func (p *objectPool[T, P]) clone(x *T) *T {
+	return P(x).Clone()
+}
+
+// withoutActual returns a clone of the given object without actual state.
+func (p *objectPool[T, P]) withoutActual(x *T) *T {
+	result := p.clone(x)
+	p.options.setActual(result, new(T))
+	return result
+}
+
+// SetRequest stores the requested state of the given object.
+func (p *objectPool[T, P]) SetRequest(x T) {
+	addr := P(&x).GetAddress()
+	p.options.metrics.SetRequestTotalCounters.WithLabelValues(string(addr)).Inc()
+	if !p.canRequest() {
+		p.log.Warn().Str("address", string(addr)).Msg("Request ignored by pool that cannot be requested")
+		return
+	}
+	if onRequest := p.options.onRequest; onRequest != nil {
+		onRequest(&x)
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if e, found := p.entries[addr]; found {
+		p.options.setRequest(e, &x)
+	} else {
+		p.entries[addr] = p.withoutActual(&x)
+	}
+}
+
+// GetRequests returns all entries that have a requested state (without actual state).
+func (p *objectPool[T, P]) GetRequests() []T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	result := make([]T, 0, len(p.entries))
+	if !p.canRequest() {
+		return result
+	}
+	for _, e := range p.entries {
+		if p.options.hasRequest(e) {
+			result = append(result, *p.withoutActual(e))
+		}
+	}
+	return result
+}
+
+// Get returns the entry with given address.
+func (p *objectPool[T, P]) Get(addr api.ObjectAddress) (T, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if e, found := p.entries[addr]; found {
+		return *p.clone(e), true
+	}
+	var zero T
+	return zero, false
+}
+
+// GetAll returns all entries.
+func (p *objectPool[T, P]) GetAll() []T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	result := make([]T, 0, len(p.entries))
+	for _, e := range p.entries {
+		result = append(result, *p.clone(e))
+	}
+	return result
+}
+
+// SetActual stores the actual state of the given object & publishes
+// the updated entry.
+func (p *objectPool[T, P]) SetActual(x T) {
+	addr := P(&x).GetAddress()
+	p.options.metrics.SetActualTotalCounters.WithLabelValues(string(addr)).Inc()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	e, found := p.entries[addr]
+	if !found {
+		// Check global address
+		_, local, _ := api.SplitAddress(addr)
+		e, found = p.entries[api.JoinModuleLocal(api.GlobalModuleID, local)]
+	}
+	if !found {
+		if p.canRequest() {
+			// Apparently we do not care for this object
+			return
+		}
+		e = p.clone(&x)
+		p.entries[addr] = e
+	} else {
+		p.options.setActual(e, &x)
+	}
+	p.actualChanges.Publish(*p.clone(e))
+}
+
+// SubActual subscribes to changes of the actual states of objects that match
+// the given filter. All known actual states are sent directly after subscribing.
+func (p *objectPool[T, P]) SubActual(enabled bool, policy SubscriberPolicy, filter ModuleFilter) (chan T, context.CancelFunc) {
+	p.options.metrics.SubActualTotalCounter.Inc()
+	if !enabled {
+		return disabledSubscription[T]()
+	}
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	s := p.actualChanges.Subscribe(policy, func(x T) bool {
+		return filter.Matches(P(&x).GetAddress())
+	}, nil)
+	// Push all known actual states
+	for _, e := range p.entries {
+		if p.options.hasActual(e) {
+			s.Push(*p.clone(e))
+		}
+	}
+	return s.C(), s.Close
+}
@@ -0,0 +1,422 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/rs/zerolog"
+)
+
+func newTestSwitch(addr api.ObjectAddress, request, actual *api.SwitchState) api.Switch {
+	return api.Switch{Address: addr, Request: request, Actual: actual}
+}
+
+var (
+	straight = &api.SwitchState{Direction: api.SwitchDirection_STRAIGHT}
+	off      = &api.SwitchState{Direction: api.SwitchDirection_OFF}
+)
+
+func TestObjectPoolSetActual(t *testing.T) {
+	tests := []struct {
+		name string
+		// Addresses of the switches requested before setting the actual state
+		requested []api.ObjectAddress
+		// Address of the actual state
+		actual api.ObjectAddress
+		// Address of the entry expected to contain the actual state (empty if dropped)
+		expected api.ObjectAddress
+	}{
+		{
+			name:      "requested address",
+			requested: []api.ObjectAddress{"m1/s1"},
+			actual:    "m1/s1",
+			expected:  "m1/s1",
+		},
+		{
+			name:      "global address fallback",
+			requested: []api.ObjectAddress{"GLOBAL/s1"},
+			actual:    "m1/s1",
+			expected:  "GLOBAL/s1",
+		},
+		{
+			name:      "module address preferred over global address",
+			requested: []api.ObjectAddress{"GLOBAL/s1", "m1/s1"},
+			actual:    "m1/s1",
+			expected:  "m1/s1",
+		},
+		{
+			name:      "other local address is dropped",
+			requested: []api.ObjectAddress{"GLOBAL/s2"},
+			actual:    "m1/s1",
+		},
+		{
+			name:   "unknown address is dropped",
+			actual: "m1/s1",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newSwitchPool(zerolog.Nop())
+			for _, addr := range tc.requested {
+				p.SetRequest(newTestSwitch(addr, off, nil))
+			}
+			p.SetActual(newTestSwitch(tc.actual, nil, straight))
+
+			withActual := 0
+			for _, x := range p.GetAll() {
+				if x.GetActual() != nil {
+					withActual++
+				}
+			}
+			if tc.expected == "" {
+				if withActual != 0 {
+					t.Errorf("expected actual state to be dropped, found %d entries with actual state", withActual)
+				}
+				if len(p.GetAll()) != len(tc.requested) {
+					t.Errorf("expected %d entries, got %d", len(tc.requested), len(p.GetAll()))
+				}
+				return
+			}
+			if withActual != 1 {
+				t.Errorf("expected 1 entry with actual state, got %d", withActual)
+			}
+			x, found := p.Get(tc.expected)
+			if !found {
+				t.Fatalf("expected entry %s", tc.expected)
+			}
+			if !x.GetActual().Equal(straight) {
+				t.Errorf("expected actual %v, got %v", straight, x.GetActual())
+			}
+			if !x.GetRequest().Equal(off) {
+				t.Errorf("expected request %v to be kept, got %v", off, x.GetRequest())
+			}
+		})
+	}
+}
+
+func TestObjectPoolSetActualAutoCreate(t *testing.T) {
+	tests := []struct {
+		name string
+		// Sets one or more actual states for given address in a new pool.
+		// Returns whether the pool contains the entry afterwards, the value of
+		// its actual state (-1 if none) & the number of entries in the pool.
+		setActual func(addr api.ObjectAddress) (found bool, value int32, count int)
+		// Set if the entry is expected to be found
+		found bool
+		// Expected value of the actual state
+		value int32
+	}{
+		{
+			name: "unknown sensor is created",
+			setActual: func(addr api.ObjectAddress) (bool, int32, int) {
+				p := newSensorPool(zerolog.Nop())
+				p.SetActual(api.Sensor{Address: addr, Actual: &api.SensorState{Value: 1}})
+				x, found := p.Get(addr)
+				return found, sensorValue(x), len(p.GetAll())
+			},
+			found: true,
+			value: 1,
+		},
+		{
+			name: "existing sensor is updated",
+			setActual: func(addr api.ObjectAddress) (bool, int32, int) {
+				p := newSensorPool(zerolog.Nop())
+				p.SetActual(api.Sensor{Address: addr, Actual: &api.SensorState{Value: 1}})
+				p.SetActual(api.Sensor{Address: addr, Actual: &api.SensorState{Value: 0}})
+				x, found := p.Get(addr)
+				return found, sensorValue(x), len(p.GetAll())
+			},
+			found: true,
+			value: 0,
+		},
+		{
+			name: "unknown switch is dropped",
+			setActual: func(addr api.ObjectAddress) (bool, int32, int) {
+				p := newSwitchPool(zerolog.Nop())
+				p.SetActual(newTestSwitch(addr, nil, straight))
+				x, found := p.Get(addr)
+				return found, switchValue(x), len(p.GetAll())
+			},
+			value: -1,
+		},
+		{
+			name: "requested switch is updated",
+			setActual: func(addr api.ObjectAddress) (bool, int32, int) {
+				p := newSwitchPool(zerolog.Nop())
+				p.SetRequest(newTestSwitch(addr, off, nil))
+				p.SetActual(newTestSwitch(addr, nil, straight))
+				p.SetActual(newTestSwitch(addr, nil, off))
+				x, found := p.Get(addr)
+				return found, switchValue(x), len(p.GetAll())
+			},
+			found: true,
+			value: int32(api.SwitchDirection_OFF),
+		},
+		{
+			name: "unknown output is dropped",
+			setActual: func(addr api.ObjectAddress) (bool, int32, int) {
+				p := newOutputPool(zerolog.Nop())
+				p.SetActual(api.Output{Address: addr, Actual: &api.OutputState{Value: 1}})
+				x, found := p.Get(addr)
+				return found, outputValue(x), len(p.GetAll())
+			},
+			value: -1,
+		},
+		{
+			name: "requested output is updated",
+			setActual: func(addr api.ObjectAddress) (bool, int32, int) {
+				p := newOutputPool(zerolog.Nop())
+				p.SetRequest(api.Output{Address: addr, Request: &api.OutputState{Value: 2}})
+				p.SetActual(api.Output{Address: addr, Actual: &api.OutputState{Value: 1}})
+				p.SetActual(api.Output{Address: addr, Actual: &api.OutputState{Value: 2}})
+				x, found := p.Get(addr)
+				return found, outputValue(x), len(p.GetAll())
+			},
+			found: true,
+			value: 2,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			found, value, count := tc.setActual("m1/x1")
+			if found != tc.found {
+				t.Errorf("expected found=%v, got %v", tc.found, found)
+			}
+			if value != tc.value {
+				t.Errorf("expected actual value %d, got %d", tc.value, value)
+			}
+			expectedCount := 0
+			if tc.found {
+				expectedCount = 1
+			}
+			if count != expectedCount {
+				t.Errorf("expected %d entries, got %d", expectedCount, count)
+			}
+		})
+	}
+}
+
+// sensorValue returns the actual value of the given sensor (-1 if none).
+func sensorValue(x api.Sensor) int32 {
+	if x.GetActual() == nil {
+		return -1
+	}
+	return x.GetActual().GetValue()
+}
+
+// switchValue returns the actual direction of the given switch (-1 if none).
+func switchValue(x api.Switch) int32 {
+	if x.GetActual() == nil {
+		return -1
+	}
+	return int32(x.GetActual().GetDirection())
+}
+
+// outputValue returns the actual value of the given output (-1 if none).
+func outputValue(x api.Output) int32 {
+	if x.GetActual() == nil {
+		return -1
+	}
+	return x.GetActual().GetValue()
+}
+
+func TestObjectPoolGetRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested []api.Switch
+		actuals   []api.Switch
+		expected  []api.ObjectAddress
+	}{
+		{
+			name: "no requests",
+		},
+		{
+			name:      "requests without actual state",
+			requested: []api.Switch{newTestSwitch("m1/s1", off, nil), newTestSwitch("m2/s1", straight, nil)},
+			expected:  []api.ObjectAddress{"m1/s1", "m2/s1"},
+		},
+		{
+			name:      "actual state is stripped",
+			requested: []api.Switch{newTestSwitch("m1/s1", off, nil), newTestSwitch("GLOBAL/s2", straight, nil)},
+			actuals:   []api.Switch{newTestSwitch("m1/s1", nil, straight), newTestSwitch("m2/s2", nil, off)},
+			expected:  []api.ObjectAddress{"GLOBAL/s2", "m1/s1"},
+		},
+		{
+			name:    "actual states only",
+			actuals: []api.Switch{newTestSwitch("m1/s1", nil, straight)},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newSwitchPool(zerolog.Nop())
+			requests := make(map[api.ObjectAddress]*api.SwitchState)
+			for _, x := range tc.requested {
+				p.SetRequest(x)
+				requests[x.GetAddress()] = x.GetRequest()
+			}
+			for _, x := range tc.actuals {
+				p.SetActual(x)
+			}
+			result := p.GetRequests()
+			sort.Slice(result, func(i, j int) bool { return result[i].GetAddress() < result[j].GetAddress() })
+			if len(result) != len(tc.expected) {
+				t.Fatalf("expected %d requests, got %d", len(tc.expected), len(result))
+			}
+			for i, x := range result {
+				if x.GetAddress() != tc.expected[i] {
+					t.Errorf("expected address %s, got %s", tc.expected[i], x.GetAddress())
+				}
+				if x.GetActual() != nil {
+					t.Errorf("expected no actual state for %s, got %v", x.GetAddress(), x.GetActual())
+				}
+				if !x.GetRequest().Equal(requests[x.GetAddress()]) {
+					t.Errorf("expected request %v for %s, got %v", requests[x.GetAddress()], x.GetAddress(), x.GetRequest())
+				}
+			}
+			// The pool itself must keep its actual states
+			for _, x := range tc.actuals {
+				if e, found := p.Get(x.GetAddress()); found && e.GetActual() == nil {
+					t.Errorf("expected pool to keep actual state of %s", x.GetAddress())
+				}
+			}
+		})
+	}
+}
+
+func TestObjectPoolGetRequestsNotRequestable(t *testing.T) {
+	p := newSensorPool(zerolog.Nop())
+	p.SetActual(api.Sensor{Address: "m1/x1", Actual: &api.SensorState{Value: 1}})
+	if result := p.GetRequests(); len(result) != 0 {
+		t.Errorf("expected no requests, got %v", result)
+	}
+}
+
+func TestObjectPoolSubActual(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ModuleFilter
+		// Addresses expected in the initial state (sorted)
+		initial []api.ObjectAddress
+		// Addresses of which live updates are expected (in publication order)
+		live []api.ObjectAddress
+	}{
+		{
+			name:    "no filter",
+			initial: []api.ObjectAddress{"GLOBAL/s3", "m1/s1", "m2/s2"},
+			live:    []api.ObjectAddress{"m1/s1", "m2/s2", "GLOBAL/s3"},
+		},
+		{
+			name:    "module filter",
+			filter:  "m1",
+			initial: []api.ObjectAddress{"GLOBAL/s3", "m1/s1"},
+			live:    []api.ObjectAddress{"m1/s1", "GLOBAL/s3"},
+		},
+		{
+			name:    "unknown module",
+			filter:  "m3",
+			initial: []api.ObjectAddress{"GLOBAL/s3"},
+			live:    []api.ObjectAddress{"GLOBAL/s3"},
+		},
+	}
+	all := []api.ObjectAddress{"m1/s1", "m2/s2", "GLOBAL/s3"}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newSwitchPool(zerolog.Nop())
+			for _, addr := range all {
+				p.SetRequest(newTestSwitch(addr, off, nil))
+				p.SetActual(newTestSwitch(addr, nil, off))
+			}
+			// Requested only, must not be part of the initial state
+			p.SetRequest(newTestSwitch("m1/s4", off, nil))
+
+			ch, cancel := p.SubActual(true, PolicyDropOldest, tc.filter)
+			defer cancel()
+
+			// Initial state
+			var initial []api.ObjectAddress
+			for range tc.initial {
+				x := receive(t, ch)
+				if !x.GetActual().Equal(off) {
+					t.Errorf("expected initial actual %v for %s, got %v", off, x.GetAddress(), x.GetActual())
+				}
+				initial = append(initial, x.GetAddress())
+			}
+			sort.Slice(initial, func(i, j int) bool { return initial[i] < initial[j] })
+			for i, addr := range tc.initial {
+				if initial[i] != addr {
+					t.Errorf("expected initial state of %v, got %v", tc.initial, initial)
+					break
+				}
+			}
+			expectNothing(t, ch)
+
+			// Live updates, in publication order
+			for _, addr := range all {
+				p.SetActual(newTestSwitch(addr, nil, straight))
+			}
+			for _, addr := range tc.live {
+				x := receive(t, ch)
+				if x.GetAddress() != addr {
+					t.Errorf("expected update of %s, got %s", addr, x.GetAddress())
+				}
+				if !x.GetActual().Equal(straight) {
+					t.Errorf("expected actual %v for %s, got %v", straight, x.GetAddress(), x.GetActual())
+				}
+			}
+			expectNothing(t, ch)
+		})
+	}
+}
+
+func TestObjectPoolSubActualDisabled(t *testing.T) {
+	p := newSwitchPool(zerolog.Nop())
+	p.SetRequest(newTestSwitch("m1/s1", off, nil))
+	p.SetActual(newTestSwitch("m1/s1", nil, off))
+	ch, cancel := p.SubActual(false, PolicyDropOldest, "")
+	defer cancel()
+	expectNothing(t, ch)
+}
+
+// receive waits for the next value on the given channel.
+func receive[T any](t *testing.T, ch chan T) T {
+	t.Helper()
+	select {
+	case x, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed")
+		}
+		return x
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for value")
+	}
+	var zero T
+	return zero
+}
+
+// expectNothing fails when a value arrives on the given channel soon.
+func expectNothing[T any](t *testing.T, ch chan T) {
+	t.Helper()
+	select {
+	case x, ok := <-ch:
+		if ok {
+			t.Errorf("unexpected value %v", x)
+		}
+	case <-time.After(time.Millisecond * 50):
+	}
+}
@@ -15,102 +15,19 @@
 package manager
 
 import (
-	"context"
-	"sync"
-	"time"
-
 	api "github.com/binkynet/BinkyNet/apis/v1"
-	"github.com/mattn/go-pubsub"
 	"github.com/rs/zerolog"
 )
 
-type outputPool struct {
-	mutex         sync.RWMutex
-	log           zerolog.Logger
-	entries       map[api.ObjectAddress]*api.Output
-	actualChanges *pubsub.PubSub
-}
+type outputPool = objectPool[api.Output, *api.Output]
 
 func newOutputPool(log zerolog.Logger) *outputPool {
-	return &outputPool{
-		entries:       make(map[api.ObjectAddress]*api.Output),
-		log:           log.With().Str("pool", "output").Logger(),
-		actualChanges: pubsub.New(),
-	}
-}
-
-func (p *outputPool) SetRequest(x api.Output) {
-	outputPoolMetrics.SetRequestTotalCounters.WithLabelValues(string(x.GetAddress())).Inc()
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	e, found := p.entries[x.Address]
-	if !found {
-		x.Actual = nil
-		e = x.Clone()
-		p.entries[x.Address] = e
-	} else {
-		e.Request = x.GetRequest().Clone()
-	}
-}
-
-func (p *outputPool) SetActual(x api.Output) {
-	outputPoolMetrics.SetActualTotalCounters.WithLabelValues(string(x.GetAddress())).Inc()
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	e, found := p.entries[x.Address]
-	if !found {
-		// Check global address
-		_, local, _ := api.SplitAddress(x.Address)
-		globalAddr := api.JoinModuleLocal(api.GlobalModuleID, local)
-		e, found = p.entries[globalAddr]
-		if !found {
-			// Apparently we do not care for this output
-			return
-		}
-	}
-	e.Actual = x.GetActual().Clone()
-	safePub(p.log, p.actualChanges, e.Clone())
-}
-
-func (p *outputPool) SubActual(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.Output, context.CancelFunc) {
-	outputPoolMetrics.SubActualTotalCounter.Inc()
-	c := make(chan api.Output)
-	if enabled {
-		// Subscribe
-		cb := func(msg *api.Output) {
-			if filter.Matches(msg.GetAddress()) {
-				select {
-				case c <- *msg:
-					// Done
-					outputPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
-				case <-time.After(timeout):
-					p.log.Error().
-						Dur("timeout", timeout).
-						Str("address", string(msg.GetAddress())).
-						Msg("Failed to deliver output actual to channel")
-					outputPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
-				}
-			}
-		}
-		p.actualChanges.Sub(cb)
-		// Publish all known actual states
-		p.mutex.RLock()
-		for _, output := range p.entries {
-			if output.GetActual() != nil && filter.Matches(output.GetAddress()) {
-				go cb(output.Clone())
-			}
-		}
-		p.mutex.RUnlock()
-		// Return channel & cancel function
-		return c, func() {
-			p.actualChanges.Leave(cb)
-			close(c)
-		}
-	} else {
-		return c, func() {
-			close(c)
-		}
-	}
+	return newObjectPool[api.Output](log, objectPoolOptions[api.Output]{
+		name:       "output",
+		metrics:    outputPoolMetrics,
+		hasRequest: func(x *api.Output) bool { return x.GetRequest() != nil },
+		setRequest: func(target, source *api.Output) { target.Request = source.GetRequest().Clone() },
+		hasActual:  func(x *api.Output) bool { return x.GetActual() != nil },
+		setActual:  func(target, source *api.Output) { target.Actual = source.GetActual().Clone() },
+	})
 }
@@ -14,25 +14,13 @@
 
 package manager
 
-import (
-	"time"
+import "context"
 
-	"github.com/mattn/go-pubsub"
-	"github.com/rs/zerolog"
-)
-
-const (
-	pubTimeout = time.Millisecond * 20
-)
-
-// safePub publishes the given value into the given pool, checking how long it took to publish.
-func safePub(log zerolog.Logger, pool *pubsub.PubSub, value interface{}) {
-	start := time.Now()
-	pool.Pub(value)
-	if delta := time.Since(start); delta > pubTimeout {
-		log.Error().
-			Str("timeout", pubTimeout.String()).
-			Str("actual", delta.String()).
-			Msg("Pub into pool took too long")
+// disabledSubscription returns a channel that never receives values & a cancel
+// function that closes it.
+func disabledSubscription[T any]() (chan T, context.CancelFunc) {
+	c := make(chan T)
+	return c, func() {
+		close(c)
 	}
 }
@@ -17,10 +17,8 @@ package manager
 import (
 	"context"
 	"sync"
-	"time"
 
 	api "github.com/binkynet/BinkyNet/apis/v1"
-	"github.com/mattn/go-pubsub"
 	"github.com/rs/zerolog"
 )
 
@@ -28,19 +26,34 @@ type powerPool struct {
 	mutex          sync.RWMutex
 	log            zerolog.Logger
 	power          api.Power
-	requestChanges *pubsub.PubSub
-	actualChanges  *pubsub.PubSub
+	hasRequest     bool
+	requestChanges *broadcaster[api.Power]
+	actualChanges  *broadcaster[api.Power]
 }
 
 func newPowerPool(log zerolog.Logger) *powerPool {
+	log = log.With().Str("pool", "power").Logger()
+	key := func(api.Power) string { return "power" }
 	return &powerPool{
-		log: log.With().Str("pool", "power").Logger(),
+		log: log,
 		power: api.Power{
 			Request: &api.PowerState{},
 			Actual:  &api.PowerState{},
 		},
-		requestChanges: pubsub.New(),
-		actualChanges:  pubsub.New(),
+		requestChanges: newBroadcaster(log, broadcasterOptions[api.Power]{
+			name: "power-requests",
+			key:  key,
+		}),
+		actualChanges: newBroadcaster(log, broadcasterOptions[api.Power]{
+			name: "power-actuals",
+			key:  key,
+			onDelivered: func(api.Power) {
+				powerPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues("power").Inc()
+			},
+			onDropped: func(api.Power) {
+				powerPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues("power").Inc()
+			},
+		}),
 	}
 }
 
@@ -50,7 +63,25 @@ func (p *powerPool) SetRequest(x api.PowerState) {
 	defer p.mutex.Unlock()
 
 	p.power.Request.Enabled = x.GetEnabled()
-	safePub(p.log, p.requestChanges, p.power.Clone())
+	p.hasRequest = true
+	p.requestChanges.Publish(*p.power.Clone())
+}
+
+// GetRequest returns the requested power state.
+// Returns false if no power state has been requested yet.
+func (p *powerPool) GetRequest() (api.PowerState, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return *p.power.Request.Clone(), p.hasRequest
+}
+
+// Get returns the requested & actual power state.
+func (p *powerPool) Get() api.Power {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return *p.power.Clone()
 }
 
 func (p *powerPool) SetActual(x api.PowerState) {
@@ -59,39 +90,19 @@ func (p *powerPool) SetActual(x api.PowerState) {
 	defer p.mutex.Unlock()
 
 	p.power.Actual.Enabled = x.GetEnabled()
-	safePub(p.log, p.actualChanges, p.power.Clone())
+	p.actualChanges.Publish(*p.power.Clone())
 }
 
-func (p *powerPool) SubActual(enabled bool, timeout time.Duration) (chan api.Power, context.CancelFunc) {
+func (p *powerPool) SubActual(enabled bool, policy SubscriberPolicy) (chan api.Power, context.CancelFunc) {
 	powerPoolMetrics.SubActualTotalCounter.Inc()
-	c := make(chan api.Power)
-	if enabled {
-		// Subscribe
-		cb := func(msg *api.Power) {
-			select {
-			case c <- *msg:
-				// Done
-				powerPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues("power").Inc()
-			case <-time.After(timeout):
-				p.log.Error().
-					Dur("timeout", timeout).
-					Msg("Failed to deliver power actual to channel")
-				powerPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues("power").Inc()
-			}
-		}
-		p.actualChanges.Sub(cb)
-		// Publish known request state
-		p.mutex.RLock()
-		go cb(p.power.Clone())
-		p.mutex.RUnlock()
-		// Return channel & cancel function
-		return c, func() {
-			p.actualChanges.Leave(cb)
-			close(c)
-		}
-	} else {
-		return c, func() {
-			close(c)
-		}
+	if !enabled {
+		return disabledSubscription[api.Power]()
 	}
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	s := p.actualChanges.Subscribe(policy, nil, nil)
+	// Push known power state
+	s.Push(*p.power.Clone())
+	return s.C(), s.Close
 }
@@ -0,0 +1,215 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/rs/zerolog"
+
+	"github.com/binkynet/NetManager/service/state"
+)
+
+const (
+	// Interval between comparisons of requested & actual states
+	reconcileInterval = time.Second
+	// Time a local worker is given to apply a request, before it is resent.
+	// The time between resends doubles after every attempt.
+	reconcileInitialBackoff = time.Second * 2
+	// Maximum time between resends
+	reconcileMaxBackoff = time.Minute
+)
+
+// DivergentObject is an object of which the actual state differs from
+// its requested state.
+type DivergentObject struct {
+	// Domain of the object (loc, output or switch)
+	Domain state.Domain
+	// Address of the object
+	Address api.ObjectAddress
+	// Time the divergence was first detected
+	Since time.Time
+	// Number of times the request has been resent
+	Attempts int
+	// Time of the next resend
+	NextAttemptAt time.Time
+
+	// Requested state (as text) the divergence was detected for
+	request string
+}
+
+type reconcileKey struct {
+	domain  state.Domain
+	address api.ObjectAddress
+}
+
+// reconciler keeps track of divergent objects.
+type reconciler struct {
+	mutex   sync.Mutex
+	objects map[reconcileKey]*DivergentObject
+}
+
+// GetAll returns all divergent objects, sorted by domain & address.
+func (r *reconciler) GetAll() []DivergentObject {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := make([]DivergentObject, 0, len(r.objects))
+	for _, x := range r.objects {
+		result = append(result, *x)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Domain != result[j].Domain {
+			return result[i].Domain < result[j].Domain
+		}
+		return result[i].Address < result[j].Address
+	})
+	return result
+}
+
+// GetDivergentObjects returns all objects of which the actual state differs
+// from the requested state.
+func (m *manager) GetDivergentObjects() []DivergentObject {
+	return m.reconciler.GetAll()
+}
+
+// runReconciler periodically compares the requested & actual states of all
+// locs, outputs & switches and resends requests that have not been applied,
+// until the given context is cancelled.
+func (m *manager) runReconciler(ctx context.Context, log zerolog.Logger) {
+	log = log.With().Str("component", "reconciler").Logger()
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			m.reconcile(now, log)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reconcile compares the requested & actual states of all locs, outputs &
+// switches once and resends requests that are due.
+// Requests are resent after releasing the lock of the reconciler.
+func (m *manager) reconcile(now time.Time, log zerolog.Logger) {
+	locs := m.locPool.GetAll()
+	outputs := m.outputPool.GetAll()
+	switches := m.switchPool.GetAll()
+
+	r := &m.reconciler
+	var resends []func()
+	r.mutex.Lock()
+	if r.objects == nil {
+		r.objects = make(map[reconcileKey]*DivergentObject)
+	}
+	seen := make(map[reconcileKey]struct{})
+	// check updates the divergence of a single object & collects its request
+	// to be resent when due.
+	check := func(domain state.Domain, addr api.ObjectAddress, request string, diverges bool, resend func()) {
+		key := reconcileKey{domain: domain, address: addr}
+		x, found := r.objects[key]
+		if !diverges {
+			if found && x.Attempts > 0 {
+				log.Info().
+					Str("domain", string(domain)).
+					Str("address", string(addr)).
+					Int("attempts", x.Attempts).
+					Msg("Object converged after resending request")
+			}
+			return
+		}
+		seen[key] = struct{}{}
+		if !found || x.request != request {
+			// New divergence (or new request)
+			r.objects[key] = &DivergentObject{
+				Domain:        domain,
+				Address:       addr,
+				Since:         now,
+				NextAttemptAt: now.Add(reconcileInitialBackoff),
+				request:       request,
+			}
+			return
+		}
+		if now.Before(x.NextAttemptAt) {
+			return
+		}
+		x.Attempts++
+		backoff := reconcileInitialBackoff << x.Attempts
+		if backoff <= 0 || backoff > reconcileMaxBackoff {
+			backoff = reconcileMaxBackoff
+		}
+		x.NextAttemptAt = now.Add(backoff)
+		log.Warn().
+			Str("domain", string(domain)).
+			Str("address", string(addr)).
+			Int("attempt", x.Attempts).
+			Dur("divergent", now.Sub(x.Since)).
+			Msg("Actual state differs from requested state, resending request")
+		reconcileResendTotalCounters.WithLabelValues(string(domain), string(addr)).Inc()
+		resends = append(resends, resend)
+	}
+
+	for _, x := range locs {
+		if req := x.GetRequest(); req != nil {
+			actual := x.GetActual()
+			x.Actual = nil
+			check(state.DomainLoc, x.GetAddress(), req.String(), actual == nil || !req.Equal(actual), func() {
+				m.sendLocRequest(x)
+			})
+		}
+	}
+	for _, x := range outputs {
+		if req := x.GetRequest(); req != nil {
+			actual := x.GetActual()
+			x.Actual = nil
+			check(state.DomainOutput, x.GetAddress(), req.String(), actual == nil || !req.Equal(actual), func() {
+				m.sendOutputRequest(x)
+			})
+		}
+	}
+	for _, x := range switches {
+		if req := x.GetRequest(); req != nil {
+			actual := x.GetActual()
+			x.Actual = nil
+			check(state.DomainSwitch, x.GetAddress(), req.String(), actual == nil || !req.Equal(actual), func() {
+				m.sendSwitchRequest(x)
+			})
+		}
+	}
+
+	// Remove objects that are no longer divergent
+	counts := make(map[state.Domain]int)
+	for key := range r.objects {
+		if _, found := seen[key]; !found {
+			delete(r.objects, key)
+		} else {
+			counts[key.domain]++
+		}
+	}
+	for _, domain := range []state.Domain{state.DomainLoc, state.DomainOutput, state.DomainSwitch} {
+		divergentObjectsGauges.WithLabelValues(string(domain)).Set(float64(counts[domain]))
+	}
+	r.mutex.Unlock()
+
+	for _, resend := range resends {
+		resend()
+	}
+}
@@ -0,0 +1,198 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/binkynet/NetManager/service/config"
+)
+
+const (
+	// Timeout of a reset request send to a local worker after a reconfiguration.
+	reconfigureResetTimeout = time.Second * 10
+)
+
+var (
+	// ErrNoConfigHistory is returned when the history of a configuration is
+	// requested from a configuration registry that does not keep a history.
+	ErrNoConfigHistory = errors.New("configuration registry does not keep a history")
+	// ErrNoStateStore is returned when an operation requires a state store
+	// and the manager has none.
+	ErrNoStateStore = errors.New("network manager has no state store")
+)
+
+// configureLocalWorker loads the configuration of the local worker with given ID
+// from the registry and sets it as the requested state of that local worker.
+// Returns true if the requested state has changed.
+func (m *manager) configureLocalWorker(ctx context.Context, id string) (bool, error) {
+	if m.ConfigRegistry == nil {
+		return false, nil
+	}
+	conf, err := m.ConfigRegistry.Get(id)
+	if err != nil {
+		return false, fmt.Errorf("failed to load configuration of local worker [%s]: %w", id, err)
+	}
+	if current, found := m.localWorkerPool.GetRequest(id); found && current.Equal(&conf) {
+		// No changes
+		return false, nil
+	}
+	if err := m.localWorkerPool.SetRequest(ctx, api.LocalWorker{
+		Id:      id,
+		Request: &conf,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// isConfigFailed returns true if the configuration of the local worker with
+// given ID failed to load and has not changed since.
+func (m *manager) isConfigFailed(id string) bool {
+	m.configFailedMutex.Lock()
+	defer m.configFailedMutex.Unlock()
+	_, found := m.configFailed[id]
+	return found
+}
+
+// setConfigFailed records whether the configuration of the local worker with
+// given ID failed to load.
+func (m *manager) setConfigFailed(id string, failed bool) {
+	m.configFailedMutex.Lock()
+	defer m.configFailedMutex.Unlock()
+	if failed {
+		m.configFailed[id] = struct{}{}
+	} else {
+		delete(m.configFailed, id)
+	}
+}
+
+// reconfigureLocalWorker reloads the configuration of the local worker with given ID
+// and pushes it to the local worker when it has changed.
+func (m *manager) reconfigureLocalWorker(ctx context.Context, id string) {
+	log := m.Log.With().Str("id", id).Logger()
+	changed, err := m.configureLocalWorker(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to reconfigure local worker")
+		lwReconfigureFailedTotalCounters.WithLabelValues(id).Inc()
+		m.setConfigFailed(id, true)
+		return
+	}
+	if !changed {
+		log.Debug().Msg("Configuration of local worker is unchanged")
+		return
+	}
+	lwReconfigureTotalCounters.WithLabelValues(id).Inc()
+	m.pushLocalWorkerConfiguration(id)
+}
+
+// pushLocalWorkerConfiguration ensures that the local worker with given ID
+// applies its (changed) requested configuration.
+// Local workers that watch their requested state receive the new configuration
+// through WatchLocalWorkers, all others are requested to reset themselves.
+func (m *manager) pushLocalWorkerConfiguration(id string) {
+	log := m.Log.With().Str("id", id).Logger()
+	info, _, _, found := m.localWorkerPool.GetInfo(id)
+	if !found {
+		// Local worker has not registered yet, it will get its configuration once it does
+		return
+	}
+	if m.localWorkerPool.HasRequestWatcher(id) {
+		// Local worker is notified through WatchLocalWorkers
+		log.Info().Msg("Pushed new configuration to local worker")
+		return
+	}
+	if !info.GetSupportsReset() {
+		log.Warn().Msg("Local worker does not watch configuration changes and does not support reset")
+		return
+	}
+	log.Info().Msg("Requesting reset of local worker to apply new configuration")
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), reconfigureResetTimeout)
+		defer cancel()
+		if err := m.localWorkerPool.RequestReset(ctx, id); err != nil {
+			log.Error().Err(err).Msg("Failed to reset local worker after reconfiguration")
+		}
+	}()
+}
+
+// ReconfigureAllLocalWorkers forces all local workers to reconfigure,
+// even when their configuration has not changed.
+// This is done by incrementing the configuration generation, which is part
+// of the configuration hash.
+// Without a state store, the generation would be reset when the manager restarts,
+// reconfiguring all local workers once more, so ErrNoStateStore is returned instead.
+func (m *manager) ReconfigureAllLocalWorkers(ctx context.Context) error {
+	if m.StateStore == nil {
+		return ErrNoStateStore
+	}
+	m.generationMutex.Lock()
+	generation := m.generation + 1
+	if err := saveConfigGeneration(m.StateStore, generation); err != nil {
+		m.generationMutex.Unlock()
+		return fmt.Errorf("failed to save configuration generation: %w", err)
+	}
+	m.generation = generation
+	ids := m.localWorkerPool.SetHashPrefix(configHashPrefix(m.identity, generation))
+	m.generationMutex.Unlock()
+
+	m.Log.Info().
+		Uint64("generation", generation).
+		Int("local-workers", len(ids)).
+		Msg("Forcing reconfiguration of all local workers")
+	for _, id := range ids {
+		lwReconfigureTotalCounters.WithLabelValues(id).Inc()
+		m.pushLocalWorkerConfiguration(id)
+	}
+	return nil
+}
+
+// GetLocalWorkerConfigHistory returns the revisions of the configuration
+// of the local worker with given ID. Newest revisions come first.
+func (m *manager) GetLocalWorkerConfigHistory(id string) ([]config.Revision, error) {
+	registry, err := m.historyRegistry()
+	if err != nil {
+		return nil, err
+	}
+	return registry.History(id)
+}
+
+// RollbackLocalWorkerConfig configures the local worker with given ID with its
+// configuration at the given revision.
+// If revision is empty, the local worker follows the latest configuration again.
+func (m *manager) RollbackLocalWorkerConfig(ctx context.Context, id, revision string) error {
+	registry, err := m.historyRegistry()
+	if err != nil {
+		return err
+	}
+	m.Log.Info().
+		Str("id", id).
+		Str("revision", revision).
+		Msg("Rolling back configuration of local worker")
+	return registry.Rollback(ctx, id, revision)
+}
+
+// historyRegistry returns the configuration registry if it keeps a history.
+func (m *manager) historyRegistry() (config.HistoryRegistry, error) {
+	registry, ok := m.ConfigRegistry.(config.HistoryRegistry)
+	if !ok {
+		return nil, ErrNoConfigHistory
+	}
+	return registry, nil
+}
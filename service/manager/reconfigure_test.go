@@ -0,0 +1,125 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+)
+
+// resetRecorder is a local worker service client that records reset requests.
+type resetRecorder struct {
+	api.LocalWorkerServiceClient
+	resets chan struct{}
+}
+
+// Reset records the reset request.
+func (c *resetRecorder) Reset(ctx context.Context, in *api.Empty, opts ...grpc.CallOption) (*api.Empty, error) {
+	c.resets <- struct{}{}
+	return &api.Empty{}, nil
+}
+
+func TestPushLocalWorkerConfiguration(t *testing.T) {
+	const (
+		id         = "w1"
+		workerAddr = "10.0.0.1"
+	)
+	tests := []struct {
+		name string
+		// Subscribes to the requests of the local worker in given pool.
+		// Returns a function that ends the subscription.
+		subscribe func(p *localWorkerPool) context.CancelFunc
+		// Set if a reset of the local worker is expected
+		reset bool
+	}{
+		{
+			name:      "no subscribers",
+			subscribe: func(p *localWorkerPool) context.CancelFunc { return func() {} },
+			reset:     true,
+		},
+		{
+			name: "foreign subscriber with same filter",
+			subscribe: func(p *localWorkerPool) context.CancelFunc {
+				_, cancel := p.SubRequests(true, PolicyDropOldest, id)
+				return cancel
+			},
+			reset: true,
+		},
+		{
+			name: "request watcher from other address",
+			subscribe: func(p *localWorkerPool) context.CancelFunc {
+				return p.AddRequestWatcher(id, "10.0.0.2")
+			},
+			reset: true,
+		},
+		{
+			name: "request watcher of other local worker",
+			subscribe: func(p *localWorkerPool) context.CancelFunc {
+				return p.AddRequestWatcher("w2", workerAddr)
+			},
+			reset: true,
+		},
+		{
+			name: "request watcher ended",
+			subscribe: func(p *localWorkerPool) context.CancelFunc {
+				p.AddRequestWatcher(id, workerAddr)()
+				return func() {}
+			},
+			reset: true,
+		},
+		{
+			name: "local worker watches its own requests",
+			subscribe: func(p *localWorkerPool) context.CancelFunc {
+				return p.AddRequestWatcher(id, workerAddr)
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newLocalWorkerPool(zerolog.Nop(), nil, "")
+			lw := api.LocalWorker{
+				Id:     id,
+				Actual: &api.LocalWorkerInfo{Id: id, SupportsReset: true},
+			}
+			if _, err := p.SetActual(context.Background(), lw, workerAddr); err != nil {
+				t.Fatalf("SetActual failed: %v", err)
+			}
+			client := &resetRecorder{resets: make(chan struct{}, 1)}
+			p.workers[id].client = client
+			m := &manager{
+				Dependencies:    Dependencies{Log: zerolog.Nop()},
+				localWorkerPool: p,
+			}
+			cancel := tc.subscribe(p)
+			defer cancel()
+
+			m.pushLocalWorkerConfiguration(id)
+			if tc.reset {
+				select {
+				case <-client.resets:
+				case <-time.After(time.Second):
+					t.Fatal("expected local worker to be reset")
+				}
+			} else {
+				expectNothing(t, client.resets)
+			}
+		})
+	}
+}
@@ -15,82 +15,18 @@
 package manager
 
 import (
-	"context"
-	"sync"
-	"time"
-
 	api "github.com/binkynet/BinkyNet/apis/v1"
-	"github.com/mattn/go-pubsub"
 	"github.com/rs/zerolog"
 )
 
-type sensorPool struct {
-	mutex         sync.RWMutex
-	log           zerolog.Logger
-	entries       map[api.ObjectAddress]*api.Sensor
-	actualChanges *pubsub.PubSub
-}
+type sensorPool = objectPool[api.Sensor, *api.Sensor]
 
 func newSensorPool(log zerolog.Logger) *sensorPool {
-	return &sensorPool{
-		entries:       make(map[api.ObjectAddress]*api.Sensor),
-		log:           log.With().Str("pool", "sensor").Logger(),
-		actualChanges: pubsub.New(),
-	}
-}
-
-func (p *sensorPool) SetActual(x api.Sensor) {
-	sensorPoolMetrics.SetActualTotalCounters.WithLabelValues(string(x.GetAddress())).Inc()
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-
-	e, found := p.entries[x.Address]
-	if !found {
-		e = x.Clone()
-		p.entries[x.Address] = e
-	} else {
-		e.Actual = x.GetActual().Clone()
-	}
-	safePub(p.log, p.actualChanges, e.Clone())
-}
-
-func (p *sensorPool) SubActual(enabled bool, timeout time.Duration, filter ModuleFilter) (chan api.Sensor, context.CancelFunc) {
-	sensorPoolMetrics.SubActualTotalCounter.Inc()
-	c := make(chan api.Sensor)
-	if enabled {
-		// Subscribe
-		cb := func(msg *api.Sensor) {
-			if filter.Matches(msg.GetAddress()) {
-				select {
-				case c <- *msg:
-					// Done
-					sensorPoolMetrics.SubActualMessagesTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
-				case <-time.After(timeout):
-					p.log.Error().
-						Dur("timeout", timeout).
-						Str("address", string(msg.GetAddress())).
-						Msg("Failed to deliver sensor actual to channel")
-					sensorPoolMetrics.SubActualMessagesFailedTotalCounters.WithLabelValues(string(msg.GetAddress())).Inc()
-				}
-			}
-		}
-		p.actualChanges.Sub(cb)
-		// Publish all known actual states
-		p.mutex.RLock()
-		for _, sensor := range p.entries {
-			if sensor.GetActual() != nil && filter.Matches(sensor.GetAddress()) {
-				go cb(sensor.Clone())
-			}
-		}
-		p.mutex.RUnlock()
-		// Return channel & cancel function
-		return c, func() {
-			p.actualChanges.Leave(cb)
-			close(c)
-		}
-	} else {
-		return c, func() {
-			close(c)
-		}
-	}
+	// Sensors cannot be requested
+	return newObjectPool[api.Sensor](log, objectPoolOptions[api.Sensor]{
+		name:      "sensor",
+		metrics:   sensorPoolMetrics,
+		hasActual: func(x *api.Sensor) bool { return x.GetActual() != nil },
+		setActual: func(target, source *api.Sensor) { target.Actual = source.GetActual().Clone() },
+	})
 }
@@ -0,0 +1,197 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	api "github.com/binkynet/BinkyNet/apis/v1"
+	"github.com/binkynet/NetManager/service/state"
+)
+
+const (
+	// Key of the (single) power state in the state store
+	powerStateKey = "power"
+	// Time to collect requested states before writing them to the state store
+	persistDelay = time.Millisecond * 500
+)
+
+// pendingStateKey identifies a requested state waiting to be written to the state store.
+type pendingStateKey struct {
+	domain state.Domain
+	key    string
+}
+
+// restoreState loads all requested states from the state store into the pools.
+func (m *manager) restoreState() error {
+	if m.StateStore == nil {
+		return nil
+	}
+	powers, err := state.Load[api.PowerState](m.StateStore, state.DomainPower)
+	if err != nil {
+		return err
+	}
+	if x, found := powers[powerStateKey]; found {
+		m.powerPool.SetRequest(x)
+	}
+	locs, err := state.Load[api.Loc](m.StateStore, state.DomainLoc)
+	if err != nil {
+		return err
+	}
+	for _, x := range locs {
+		m.locPool.SetRequest(x)
+	}
+	outputs, err := state.Load[api.Output](m.StateStore, state.DomainOutput)
+	if err != nil {
+		return err
+	}
+	for _, x := range outputs {
+		m.outputPool.SetRequest(x)
+	}
+	switches, err := state.Load[api.Switch](m.StateStore, state.DomainSwitch)
+	if err != nil {
+		return err
+	}
+	for _, x := range switches {
+		m.switchPool.SetRequest(x)
+	}
+	m.Log.Info().
+		Bool("power", len(powers) > 0).
+		Int("locs", len(locs)).
+		Int("outputs", len(outputs)).
+		Int("switches", len(switches)).
+		Msg("Restored requested state")
+	return nil
+}
+
+// persistRequest queues the given requested state to be written to the state store (if any).
+// Only the last requested state of every object is kept; it is written by runPersister
+// together with all other requested states that arrived within persistDelay.
+func (m *manager) persistRequest(domain state.Domain, key string, msg state.Message) {
+	if m.StateStore == nil {
+		return
+	}
+	data, err := msg.Marshal()
+	if err != nil {
+		m.Log.Warn().Err(err).
+			Str("domain", string(domain)).
+			Str("key", key).
+			Msg("Failed to encode requested state")
+		return
+	}
+	m.pendingStateMutex.Lock()
+	m.pendingState[pendingStateKey{domain: domain, key: key}] = data
+	m.pendingStateMutex.Unlock()
+	select {
+	case m.pendingStateSignal <- struct{}{}:
+	default:
+		// Already signalled
+	}
+}
+
+// runPersister writes queued requested states to the state store until
+// the given context is cancelled.
+func (m *manager) runPersister(ctx context.Context) {
+	if m.StateStore == nil {
+		return
+	}
+	for {
+		select {
+		case <-m.pendingStateSignal:
+			select {
+			case <-time.After(persistDelay):
+				m.flushRequests()
+			case <-ctx.Done():
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// flushRequests writes all queued requested states to the state store
+// in a single transaction.
+func (m *manager) flushRequests() {
+	if m.StateStore == nil {
+		return
+	}
+	m.pendingStateMutex.Lock()
+	pending := m.pendingState
+	m.pendingState = make(map[pendingStateKey][]byte)
+	m.pendingStateMutex.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+	entries := make([]state.Entry, 0, len(pending))
+	for k, data := range pending {
+		entries = append(entries, state.Entry{Domain: k.domain, Key: k.key, Value: data})
+	}
+	if err := m.StateStore.PutAll(entries); err != nil {
+		m.Log.Warn().Err(err).
+			Int("count", len(entries)).
+			Msg("Failed to persist requested states")
+	}
+}
+
+// replayRequests queues all known requested states that are relevant for
+// the local worker with given ID, to be sent to that local worker.
+// This is called when a local worker (re)connects, so it does not have
+// to wait for the next request to get into the requested state.
+// Requests are sent through the queue of the local worker, so they replace
+// waiting requests for the same objects and are subject to its timeout.
+func (m *manager) replayRequests(id string) {
+	lwInfo, _, _, found := m.localWorkerPool.GetInfo(id)
+	if !found {
+		return
+	}
+	// isRelevant returns true if the object with given address can be controlled
+	// by the local worker.
+	isRelevant := func(addr api.ObjectAddress) bool {
+		moduleID, _, _ := api.SplitAddress(addr)
+		return moduleID == api.GlobalModuleID || moduleID == id
+	}
+	count := 0
+	if x, found := m.powerPool.GetRequest(); found && lwInfo.GetSupportsSetPowerRequest() {
+		m.localWorkerQueues.Enqueue(id, state.DomainPower, powerStateKey, powerRequestSender(x))
+		count++
+	}
+	if lwInfo.GetSupportsSetLocRequest() {
+		for _, x := range m.locPool.GetRequests() {
+			m.localWorkerQueues.Enqueue(id, state.DomainLoc, string(x.GetAddress()), locRequestSender(x))
+			count++
+		}
+	}
+	if lwInfo.GetSupportsSetOutputRequest() {
+		for _, x := range m.outputPool.GetRequests() {
+			if isRelevant(x.GetAddress()) {
+				m.localWorkerQueues.Enqueue(id, state.DomainOutput, string(x.GetAddress()), outputRequestSender(x))
+				count++
+			}
+		}
+	}
+	if lwInfo.GetSupportsSetSwitchRequest() {
+		for _, x := range m.switchPool.GetRequests() {
+			if isRelevant(x.GetAddress()) {
+				m.localWorkerQueues.Enqueue(id, state.DomainSwitch, string(x.GetAddress()), switchRequestSender(x))
+				count++
+			}
+		}
+	}
+	if count > 0 {
+		m.Log.Info().Str("id", id).Int("requests", count).Msg("Replaying requested state to local worker")
+	}
+}
@@ -17,7 +17,6 @@ package service
 import (
 	"context"
 	"net"
-	"time"
 
 	api "github.com/binkynet/BinkyNet/apis/v1"
 	"github.com/binkynet/NetManager/service/manager"
@@ -25,7 +24,9 @@ import (
 )
 
 const (
-	chanTimeout = time.Second * 5
+	// Policy used to deliver changes to watchers.
+	// Watchers only need the latest state of every object.
+	watchPolicy = manager.PolicyCoalesce
 )
 
 // Set the requested local worker state
@@ -40,25 +41,35 @@ func (s *service) SetLocalWorkerRequest(ctx context.Context, req *api.LocalWorke
 // Set the actual local worker state
 func (s *service) SetLocalWorkerActual(ctx context.Context, req *api.LocalWorker) (*api.Empty, error) {
 	lwMetrics.SetActualTotalCounters.WithLabelValues(req.GetId()).Inc()
-	var remoteAddr string
-	if pr, ok := peer.FromContext(ctx); ok {
-		remoteAddr = pr.Addr.String()
-		remoteAddr, _, _ = net.SplitHostPort(remoteAddr)
-	}
-	if err := s.Manager.SetLocalWorkerActual(ctx, *req, remoteAddr); err != nil {
+	if err := s.Manager.SetLocalWorkerActual(ctx, *req, remoteHost(ctx)); err != nil {
 		return nil, err
 	}
 	return &api.Empty{}, nil
 }
 
+// remoteHost returns the host of the peer of the given GRPC call context.
+func remoteHost(ctx context.Context) string {
+	if pr, ok := peer.FromContext(ctx); ok {
+		host, _, _ := net.SplitHostPort(pr.Addr.String())
+		return host
+	}
+	return ""
+}
+
 // Watch local worker changes
 func (s *service) WatchLocalWorkers(req *api.WatchOptions, server api.NetworkControlService_WatchLocalWorkersServer) error {
 	lwMetrics.WatchTotalCounter.Inc()
 	ctx := server.Context()
-	ach, acancel := s.Manager.SubscribeLocalWorkerActuals(req.GetWatchActualChanges(), chanTimeout, manager.ModuleFilter(req.GetModuleId()))
+	ach, acancel := s.Manager.SubscribeLocalWorkerActuals(req.GetWatchActualChanges(), watchPolicy, manager.ModuleFilter(req.GetModuleId()))
 	defer acancel()
-	rch, rcancel := s.Manager.SubscribeLocalWorkerRequests(req.GetWatchRequestChanges(), chanTimeout, manager.ModuleFilter(req.GetModuleId()))
+	rch, rcancel := s.Manager.SubscribeLocalWorkerRequests(req.GetWatchRequestChanges(), watchPolicy, manager.ModuleFilter(req.GetModuleId()))
 	defer rcancel()
+	if id := req.GetModuleId(); id != "" && req.GetWatchRequestChanges() {
+		// This may be the local worker watching its own configuration.
+		// The manager only counts it as such when it comes from the address
+		// the local worker reports its actual state from.
+		defer s.Manager.AddLocalWorkerRequestWatcher(id, remoteHost(ctx))()
+	}
 	for {
 		select {
 		case msg := <-ach:
@@ -132,7 +143,7 @@ func (s *service) SetClockActual(ctx context.Context, req *api.Clock) (*api.Empt
 func (s *service) WatchClock(req *api.WatchOptions, server api.NetworkControlService_WatchClockServer) error {
 	clockMetrics.WatchTotalCounter.Inc()
 	ctx := server.Context()
-	ach, acancel := s.Manager.SubscribeClockActuals(req.GetWatchActualChanges(), chanTimeout)
+	ach, acancel := s.Manager.SubscribeClockActuals(req.GetWatchActualChanges(), watchPolicy)
 	defer acancel()
 	for {
 		select {
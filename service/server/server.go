@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"net"
+	"net/http"
 	"strconv"
 	"time"
 
@@ -11,38 +12,56 @@ import (
 	"github.com/rs/zerolog"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/binkynet/BinkyNet/apis/util"
 	api "github.com/binkynet/BinkyNet/apis/v1"
+
+	"github.com/binkynet/NetManager/service/control"
 )
 
 type Server interface {
 	// Run the HTTP server until the given context is cancelled.
 	Run(ctx context.Context) error
+	// TLSConfig returns the TLS configuration of the GRPC server.
+	// Returns nil when TLS is disabled.
+	TLSConfig() *tls.Config
 }
 
 // Service ('s) that we offer
 type Service interface {
 	api.NetworkControlServiceServer
+	// LayoutControl returns the implementation of the LayoutControlService.
+	LayoutControl() control.LayoutControlServiceServer
 }
 
 type Config struct {
 	Host     string
 	GRPCPort int
-}
-
-func (c Config) createTLSConfig() (*tls.Config, error) {
-	return nil, nil
+	// Port of the HTTP server serving metrics & health (0 to disable)
+	HTTPPort int
+	// Certificate & key file used by the GRPC server.
+	// If not set (and TLSAutoFolder is not set), TLS is disabled.
+	TLSCertFile string
+	TLSKeyFile  string
+	// Folder to store a generated CA & server certificate in.
+	// Used only when TLSCertFile & TLSKeyFile are not set.
+	TLSAutoFolder string
 }
 
 // NewServer creates a new server
 func NewServer(conf Config, api Service, log zerolog.Logger) (Server, error) {
+	tlsConfig, err := conf.createTLSConfig()
+	if err != nil {
+		return nil, err
+	}
 	return &server{
 		Config:     conf,
 		log:        log.With().Str("component", "server").Logger(),
 		requestLog: log.With().Str("component", "server.requests").Logger(),
 		api:        api,
+		tlsConfig:  tlsConfig,
 	}, nil
 }
 
@@ -51,17 +70,19 @@ type server struct {
 	log        zerolog.Logger
 	requestLog zerolog.Logger
 	api        Service
+	tlsConfig  *tls.Config
+}
+
+// TLSConfig returns the TLS configuration of the GRPC server.
+// Returns nil when TLS is disabled.
+func (s *server) TLSConfig() *tls.Config {
+	return s.tlsConfig
 }
 
 // Run the HTTP server until the given context is cancelled.
 func (s *server) Run(ctx context.Context) error {
 	log := s.log
-
-	// Create TLS config
-	/*tlsConfig, err := s.Config.createTLSConfig()
-	if err != nil {
-		return err
-	}*/
+	tlsConfig := s.tlsConfig
 
 	// Prepare GRPC listener
 	grpcAddr := net.JoinHostPort(s.Host, strconv.Itoa(s.GRPCPort))
@@ -71,13 +92,32 @@ func (s *server) Run(ctx context.Context) error {
 	}
 
 	// Prepare GRPC server
-	grpcSrv := grpc.NewServer(
+	grpcOpts := []grpc.ServerOption{
 		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
 		grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor),
-	)
+	}
+	if tlsConfig != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcSrv := grpc.NewServer(grpcOpts...)
 	api.RegisterNetworkControlServiceServer(grpcSrv, s.api)
+	control.RegisterLayoutControlServiceServer(grpcSrv, s.api.LayoutControl())
 	// Register reflection service on gRPC server.
 	reflection.Register(grpcSrv)
+	// Initialize GRPC metrics
+	grpc_prometheus.Register(grpcSrv)
+
+	// Prepare HTTP listener & server
+	var httpLis net.Listener
+	var httpSrv *http.Server
+	if s.HTTPPort != 0 {
+		httpAddr := net.JoinHostPort(s.Host, strconv.Itoa(s.HTTPPort))
+		httpLis, err = net.Listen("tcp", httpAddr)
+		if err != nil {
+			log.Fatal().Msgf("failed to listen on address %s: %v", httpAddr, err)
+		}
+		httpSrv = s.newHTTPServer()
+	}
 
 	nctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -93,10 +133,27 @@ func (s *server) Run(ctx context.Context) error {
 		err := api.RegisterServiceEntry(nctx, api.ServiceTypeNetworkControl, api.ServiceInfo{
 			ApiVersion: "v1",
 			ApiPort:    int32(s.GRPCPort),
-			Secure:     false,
+			Secure:     tlsConfig != nil,
 		})
 		return util.ContextCanceledOrUnexpected(nctx, err, "NetManager.server.RegisterServiceEntry")
 	})
+	if httpSrv != nil {
+		g.Go(func() error {
+			if err := httpSrv.Serve(httpLis); err != nil && err != http.ErrServerClosed {
+				log.Warn().Err(err).Msg("failed to serve HTTP")
+				return err
+			}
+			return util.ContextCanceledOrUnexpected(nctx, nil, "NetManager.server.httpSvr")
+		})
+		g.Go(func() error {
+			err := api.RegisterServiceEntry(nctx, api.ServiceTypePrometheusProvider, api.ServiceInfo{
+				ApiVersion: "v1",
+				ApiPort:    int32(s.HTTPPort),
+				Secure:     false,
+			})
+			return util.ContextCanceledOrUnexpected(nctx, err, "NetManager.server.RegisterServiceEntry(metrics)")
+		})
+	}
 	g.Go(func() error {
 		// Wait for content cancellation
 		select {
@@ -121,6 +178,14 @@ func (s *server) Run(ctx context.Context) error {
 			log.Warn().Msg("GRPC did not close gracefully, stopping with force...")
 			grpcSrv.Stop()
 		}
+		if httpSrv != nil {
+			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second*3)
+			if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+				log.Warn().Err(err).Msg("HTTP did not close gracefully, stopping with force...")
+				httpSrv.Close()
+			}
+			shutdownCancel()
+		}
 		cancel()
 		log.Debug().Msg("Closed server, canceled context.")
 		return nil
@@ -0,0 +1,213 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// File names used in the auto TLS folder
+	autoCACertFile     = "ca.crt"
+	autoCAKeyFile      = "ca.key"
+	autoServerCertFile = "server.crt"
+	autoServerKeyFile  = "server.key"
+
+	// Validity of generated certificates
+	autoCAValidity     = time.Hour * 24 * 365 * 10
+	autoServerValidity = time.Hour * 24 * 365 * 2
+	// Generated server certificates are renewed when they expire within this period
+	autoServerRenewBefore = time.Hour * 24 * 30
+)
+
+// createTLSConfig creates the TLS configuration for the GRPC server.
+// Returns nil when TLS is not configured.
+func (c Config) createTLSConfig() (*tls.Config, error) {
+	certFile, keyFile := c.TLSCertFile, c.TLSKeyFile
+	if certFile == "" && keyFile == "" {
+		if c.TLSAutoFolder == "" {
+			return nil, nil
+		}
+		var err error
+		if certFile, keyFile, err = c.ensureAutoCertificate(); err != nil {
+			return nil, err
+		}
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// ensureAutoCertificate ensures that the auto TLS folder contains a self-signed CA
+// and a valid server certificate signed by that CA.
+// The CA certificate must be distributed to local workers & control apps.
+// Returns: path of server certificate, path of server key, error
+func (c Config) ensureAutoCertificate() (string, string, error) {
+	folder := c.TLSAutoFolder
+	if err := os.MkdirAll(folder, 0700); err != nil {
+		return "", "", err
+	}
+	caCertPath, caKeyPath := filepath.Join(folder, autoCACertFile), filepath.Join(folder, autoCAKeyFile)
+	certPath, keyPath := filepath.Join(folder, autoServerCertFile), filepath.Join(folder, autoServerKeyFile)
+
+	// Load or create CA.
+	// The CA certificate has been distributed to clients, so a new CA is only
+	// created when neither the certificate nor its key exist.
+	var caCert *x509.Certificate
+	var caKey *ecdsa.PrivateKey
+	var err error
+	caCertExists, caKeyExists := fileExists(caCertPath), fileExists(caKeyPath)
+	switch {
+	case caCertExists && caKeyExists:
+		caCert, caKey, err = loadCertificate(caCertPath, caKeyPath)
+	case caCertExists:
+		return "", "", fmt.Errorf("TLS CA key %s is missing (restore it, or remove %s to create a new CA)", caKeyPath, caCertPath)
+	case caKeyExists:
+		return "", "", fmt.Errorf("TLS CA certificate %s is missing (restore it, or remove %s to create a new CA)", caCertPath, caKeyPath)
+	default:
+		caCert, caKey, err = createCertificate(&x509.Certificate{
+			Subject:               pkix.Name{CommonName: "BinkyNet Network Manager CA"},
+			NotAfter:              time.Now().Add(autoCAValidity),
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}, nil, nil, caCertPath, caKeyPath)
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to prepare TLS CA: %w", err)
+	}
+
+	// Load or create server certificate
+	cert, _, err := loadCertificate(certPath, keyPath)
+	if err == nil && time.Until(cert.NotAfter) > autoServerRenewBefore && cert.CheckSignatureFrom(caCert) == nil {
+		return certPath, keyPath, nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return "", "", fmt.Errorf("failed to load TLS server certificate: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "BinkyNet Network Manager"},
+		NotAfter:    time.Now().Add(autoServerValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:    []string{"localhost"},
+	}
+	if hostname != "" {
+		template.DNSNames = append(template.DNSNames, hostname, hostname+".local")
+	}
+	if ip := net.ParseIP(c.Host); ip != nil && !ip.IsUnspecified() {
+		template.IPAddresses = append(template.IPAddresses, ip)
+	} else if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				template.IPAddresses = append(template.IPAddresses, ipNet.IP)
+			}
+		}
+	}
+	if _, _, err := createCertificate(template, caCert, caKey, certPath, keyPath); err != nil {
+		return "", "", fmt.Errorf("failed to create TLS server certificate: %w", err)
+	}
+	return certPath, keyPath, nil
+}
+
+// fileExists returns true if a file with given path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return !os.IsNotExist(err)
+}
+
+// loadCertificate loads a PEM encoded certificate & ECDSA key.
+func loadCertificate(certPath, keyPath string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	certBlock, _ := pem.Decode(certPEM)
+	if certBlock == nil {
+		return nil, nil, fmt.Errorf("no certificate found in %s", certPath)
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, nil, fmt.Errorf("no key found in %s", keyPath)
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// createCertificate creates a new key & certificate from the given template,
+// signed by the given parent (or self-signed if parent is nil), and stores
+// both as PEM files.
+func createCertificate(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, certPath, keyPath string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
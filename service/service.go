@@ -18,12 +18,15 @@ import (
 	model "github.com/binkynet/BinkyNet/apis/v1"
 	"github.com/rs/zerolog"
 
+	"github.com/binkynet/NetManager/service/control"
 	"github.com/binkynet/NetManager/service/manager"
 )
 
 // Service is the API exposed by this service.
 type Service interface {
 	model.NetworkControlServiceServer
+	// LayoutControl returns the implementation of the LayoutControlService.
+	LayoutControl() control.LayoutControlServiceServer
 }
 
 type Config struct {
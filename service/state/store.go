@@ -0,0 +1,135 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package state
+
+import (
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Domain identifies a group of stored messages.
+type Domain string
+
+const (
+	DomainLoc    Domain = "loc"
+	DomainOutput Domain = "output"
+	DomainPower  Domain = "power"
+	DomainSwitch Domain = "switch"
+	// DomainManager contains settings of the network manager itself
+	DomainManager Domain = "manager"
+)
+
+// Message is implemented by all (protobuf) messages that can be stored.
+type Message interface {
+	Marshal() ([]byte, error)
+	Unmarshal([]byte) error
+}
+
+// Store persists the requested state of the layout on disk,
+// so it survives restarts of the network manager.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (or creates) the store in the file at given path.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second * 5})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state store %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close the store.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// PutBytes stores the given raw value under given key in given domain.
+func (s *Store) PutBytes(domain Domain, key string, value []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(domain))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), value)
+	})
+}
+
+// Entry is a single raw value to store with PutAll.
+type Entry struct {
+	Domain Domain
+	Key    string
+	Value  []byte
+}
+
+// PutAll stores all given entries in a single transaction.
+func (s *Store) PutAll(entries []Entry) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, e := range entries {
+			b, err := tx.CreateBucketIfNotExists([]byte(e.Domain))
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(e.Key), e.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetBytes returns the raw value stored under given key in given domain.
+// Returns nil if not found.
+func (s *Store) GetBytes(domain Domain, key string) ([]byte, error) {
+	var result []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket([]byte(domain)); b != nil {
+			if v := b.Get([]byte(key)); v != nil {
+				result = append([]byte{}, v...)
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Load returns all messages stored in given domain, keyed by their key.
+func Load[T any, PT interface {
+	*T
+	Message
+}](s *Store, domain Domain) (map[string]T, error) {
+	result := make(map[string]T)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(domain))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			var msg T
+			if err := PT(&msg).Unmarshal(v); err != nil {
+				return fmt.Errorf("invalid %s entry '%s': %w", domain, k, err)
+			}
+			result[string(k)] = msg
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -15,6 +15,7 @@
 package util
 
 import (
+	"crypto/tls"
 	"net"
 	"strconv"
 	"time"
@@ -22,13 +23,25 @@ import (
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 )
 
 // DialConn prepares a connection to a service at given address.
-func DialConn(host string, port int, secure bool) (*grpc.ClientConn, error) {
+// If secure is set, the connection uses TLS with given configuration
+// (nil for system defaults) and the service must present a certificate
+// that is valid for the given server name.
+func DialConn(host string, port int, secure bool, tlsConfig *tls.Config, serverName string) (*grpc.ClientConn, error) {
 	address := net.JoinHostPort(host, strconv.Itoa(port))
 	var opts []grpc.DialOption
-	if !secure {
+	if secure {
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		} else {
+			tlsConfig = tlsConfig.Clone()
+		}
+		tlsConfig.ServerName = serverName
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
 		opts = append(opts, grpc.WithInsecure())
 	}
 	callOpts := []grpc_retry.CallOption{
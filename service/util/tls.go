@@ -0,0 +1,59 @@
+//    Copyright 2024 Ewout Prangsma
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// ClientTLSConfig holds the files used to dial secure services.
+type ClientTLSConfig struct {
+	// File containing PEM encoded CA certificates to trust.
+	// If empty, the system roots are trusted.
+	CAFile string
+	// Client certificate & key file used for mutual TLS (optional)
+	CertFile string
+	KeyFile  string
+}
+
+// NewClientTLS creates a TLS configuration for dialing secure services from
+// the given configuration.
+func NewClientTLS(conf ClientTLSConfig) (*tls.Config, error) {
+	result := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if conf.CAFile != "" {
+		pemCerts, err := os.ReadFile(conf.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pemCerts) {
+			return nil, fmt.Errorf("no certificates found in %s", conf.CAFile)
+		}
+		result.RootCAs = pool
+	}
+	if conf.CertFile != "" || conf.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		result.Certificates = []tls.Certificate{cert}
+	}
+	return result, nil
+}